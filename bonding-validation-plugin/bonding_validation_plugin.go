@@ -0,0 +1,147 @@
+// Copyright (c) 2021, AT&T Intellectual Property Inc.
+// All rights reserved.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"github.com/danos/xpath-plugins/common"
+	"github.com/danos/yang/xpath"
+	"github.com/danos/yang/xpath/xutils"
+)
+
+var RegistrationData = []xpath.CustomFunctionInfo{
+	{
+		Name:          "verify-bond-member",
+		FnPtr:         verifyBondMember,
+		Args:          []xpath.DatumTypeChecker{xpath.TypeIsNodeset},
+		RetType:       xpath.TypeIsBool,
+		DefaultRetVal: xpath.NewBoolDatum(false),
+	},
+	{
+		Name:          "bond-member-count",
+		FnPtr:         bondMemberCount,
+		Args:          []xpath.DatumTypeChecker{xpath.TypeIsNodeset},
+		RetType:       xpath.TypeIsNumber,
+		DefaultRetVal: xpath.NewNumDatum(0),
+	},
+}
+
+// Filters used to find required nodes. Values never change, so create once
+// and reuse.
+var addressFilter = common.GetFilter("address")
+var bondGroupFilter = common.GetFilter("bond-group")
+var bondingFilter = common.GetFilter("bonding")
+var dataplaneFilter = common.GetFilter("dataplane")
+var interfacesFilter = common.GetFilter("interfaces")
+var mtuFilter = common.GetFilter("mtu")
+var vifFilter = common.GetFilter("vif")
+
+// Settings a bond member must not have, as they are taken from the bond.
+var memberExcludedFilters = []xutils.XFilter{
+	addressFilter,
+	mtuFilter,
+	vifFilter,
+}
+
+// verifyBondMember - implementation of verify-bond-member(<nodeset>)
+//
+// Applied to a dataplane interface.  If the interface is a bond member, ie
+// has bond-group set, then it must:
+//
+//   - be a member of only one bond, and that bond must exist
+//   - not have any addresses, VIFs or an MTU of its own
+//
+// Replaces must statements of the form:
+//
+//   must "not(bond-group) or " +
+//        "(/interfaces/bonding[tagnode = current()/bond-group] and " +
+//        "not(address) and not(vif) and not(mtu))";
+//
+// with:
+//
+//   configd:must "verify-bond-member(.)"
+//
+func verifyBondMember(
+	args []xpath.Datum,
+) (retBool xpath.Datum) {
+
+	// Function has flexibility to be applied to '.' or any other node,
+	// rather than just the current node, but should only be applied to
+	// a single node.  So, if nodeset is empty or has multiple entries,
+	// return false.
+	ns0 := args[0].Nodeset("verify-bond-member()")
+	if len(ns0) != 1 {
+		return xpath.NewBoolDatum(false)
+	}
+	intfNode := ns0[0]
+
+	bondNodes := intfNode.XChildren(bondGroupFilter, xutils.Unsorted)
+	if len(bondNodes) == 0 {
+		return xpath.NewBoolDatum(true)
+	}
+	if len(bondNodes) > 1 {
+		return xpath.NewBoolDatum(false)
+	}
+
+	if !bondExists(intfNode.XRoot(), bondNodes[0].XValue()) {
+		return xpath.NewBoolDatum(false)
+	}
+
+	for _, filter := range memberExcludedFilters {
+		if len(intfNode.XChildren(filter, xutils.Unsorted)) != 0 {
+			return xpath.NewBoolDatum(false)
+		}
+	}
+
+	return xpath.NewBoolDatum(true)
+}
+
+// bondExists - true if there is a bonding interface with the given name.
+func bondExists(root xutils.XpathNode, bondName string) bool {
+	bondNodes := common.GetDescendantNodesFromSingleNode(
+		root, []xutils.XFilter{interfacesFilter, bondingFilter})
+	for _, bondNode := range bondNodes {
+		if bondNode.XValue() == bondName {
+			return true
+		}
+	}
+	return false
+}
+
+// bondMemberCount - implementation of bond-member-count(<nodeset>)
+//
+// Applied to a bonding interface, and returns the number of dataplane
+// interfaces that are members of it.  Typical use is checking min-links:
+//
+//   configd:must "not(min-links) or bond-member-count(.) >= min-links"
+//
+func bondMemberCount(
+	args []xpath.Datum,
+) (retNum xpath.Datum) {
+
+	// Function has flexibility to be applied to '.' or any other node,
+	// rather than just the current node, but should only be applied to
+	// a single node.  So, if nodeset is empty or has multiple entries,
+	// return 0.
+	ns0 := args[0].Nodeset("bond-member-count()")
+	if len(ns0) != 1 {
+		return xpath.NewNumDatum(0)
+	}
+	bondNode := ns0[0]
+
+	memberNodes := common.GetDescendantNodesFromSingleNode(
+		bondNode.XRoot(), []xutils.XFilter{
+			interfacesFilter,
+			dataplaneFilter,
+			bondGroupFilter,
+		})
+	count := 0
+	for _, memberNode := range memberNodes {
+		if memberNode.XValue() == bondNode.XValue() {
+			count++
+		}
+	}
+	return xpath.NewNumDatum(float64(count))
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2021, AT&T Intellectual Property. All rights reserved.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"testing"
+
+	"github.com/danos/yang/xpath"
+	"github.com/danos/yang/xpath/xpathtest"
+	"github.com/danos/yang/xpath/xutils"
+)
+
+type bondingTestSpec struct {
+	name         string
+	config       []xutils.PathType
+	startPath    string
+	expResult    bool
+	expNumResult float64
+}
+
+var bondInterfaces = []xutils.PathType{
+	{"interfaces", "bonding/tagnode+dp0bond0", "min-links+1"},
+	{"interfaces", "bonding/tagnode+dp0bond1"},
+}
+
+func TestBondMember(t *testing.T) {
+
+	tests := []bondingTestSpec{
+		{
+			name: "Not a bond member - PASS",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "mtu+9000"},
+				{"interfaces", "dataplane/tagnode+dp0s1", "vif/tagnode+10"},
+			},
+			expResult: true,
+		},
+		{
+			name: "Bond member - PASS",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1",
+					"bond-group+dp0bond0"},
+				{"interfaces", "dataplane/tagnode+dp0s1", "speed+auto"},
+			},
+			expResult: true,
+		},
+		{
+			name: "Bond member of unknown bond - FAIL",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1",
+					"bond-group+dp0bond9"},
+			},
+			expResult: false,
+		},
+		{
+			name: "Bond member of two bonds - FAIL",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1",
+					"bond-group+dp0bond0"},
+				{"interfaces", "dataplane/tagnode+dp0s1",
+					"bond-group+dp0bond1"},
+			},
+			expResult: false,
+		},
+		{
+			name: "Bond member with address - FAIL",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1",
+					"bond-group+dp0bond0"},
+				{"interfaces", "dataplane/tagnode+dp0s1", "address@dhcp"},
+			},
+			expResult: false,
+		},
+		{
+			name: "Bond member with VIF - FAIL",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1",
+					"bond-group+dp0bond0"},
+				{"interfaces", "dataplane/tagnode+dp0s1", "vif/tagnode+10"},
+			},
+			expResult: false,
+		},
+		{
+			name: "Bond member with MTU - FAIL",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1",
+					"bond-group+dp0bond0"},
+				{"interfaces", "dataplane/tagnode+dp0s1", "mtu+9000"},
+			},
+			expResult: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testTree := xpathtest.CreateTree(t,
+				append(test.config, bondInterfaces...))
+
+			testNode := testTree.FindFirstNode(
+				xutils.NewPathType("/interfaces/dataplane"))
+			ns := xpath.NewNodesetDatum([]xutils.XpathNode{testNode})
+
+			actResult := verifyBondMember(
+				[]xpath.Datum{ns}).Boolean("(unused value)")
+			if test.expResult != actResult {
+				t.Fatalf("Unexpected result for %s: exp %t, got %t\n",
+					test.name, test.expResult, actResult)
+			}
+		})
+	}
+}
+
+func TestBondMemberCount(t *testing.T) {
+
+	tests := []bondingTestSpec{
+		{
+			name:         "No members",
+			config:       []xutils.PathType{},
+			expNumResult: 0,
+		},
+		{
+			name: "Members of this and other bond",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1",
+					"bond-group+dp0bond0"},
+				{"interfaces", "dataplane/tagnode+dp0s2",
+					"bond-group+dp0bond1"},
+				{"interfaces", "dataplane/tagnode+dp0s3",
+					"bond-group+dp0bond0"},
+				{"interfaces", "dataplane/tagnode+dp0s4"},
+			},
+			expNumResult: 2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testTree := xpathtest.CreateTree(t,
+				append(test.config, bondInterfaces...))
+
+			testNode := testTree.FindFirstNode(
+				xutils.NewPathType("/interfaces/bonding"))
+			ns := xpath.NewNodesetDatum([]xutils.XpathNode{testNode})
+
+			actResult := bondMemberCount(
+				[]xpath.Datum{ns}).Number("(unused value)")
+			if test.expNumResult != actResult {
+				t.Fatalf("Unexpected result for %s: exp %v, got %v\n",
+					test.name, test.expNumResult, actResult)
+			}
+		})
+	}
+}
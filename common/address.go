@@ -0,0 +1,140 @@
+// Copyright (c) 2021, AT&T Intellectual Property Inc.
+// All rights reserved.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package common
+
+import (
+	"bytes"
+	"math"
+	"math/big"
+	"net"
+	"sort"
+	"strings"
+)
+
+// AddressRange - an inclusive range of IPv4 or IPv6 addresses.  A single
+// address is held as a range with identical start and end.
+type AddressRange struct {
+	Start net.IP
+	End   net.IP
+}
+
+// ParseAddressRange - convert a range of the form "<start>-<end>", or a
+// single address, into an AddressRange.  Returns false if either address
+// is invalid, the two are from different address families, or the start
+// is above the end.
+func ParseAddressRange(addrRange string) (AddressRange, bool) {
+	parts := strings.Split(addrRange, "-")
+	if len(parts) > 2 {
+		return AddressRange{}, false
+	}
+
+	start, ok := parseAddress(parts[0])
+	if !ok {
+		return AddressRange{}, false
+	}
+	end := start
+	if len(parts) == 2 {
+		if end, ok = parseAddress(parts[1]); !ok {
+			return AddressRange{}, false
+		}
+	}
+
+	if len(start) != len(end) || bytes.Compare(start, end) > 0 {
+		return AddressRange{}, false
+	}
+	return AddressRange{Start: start, End: end}, true
+}
+
+// parseAddress - parse an IPv4 or IPv6 address, returning IPv4 addresses in
+// their 4 byte form so that families can be told apart by length.
+func parseAddress(addr string) (net.IP, bool) {
+	ip := net.ParseIP(strings.TrimSpace(addr))
+	if ip == nil {
+		return nil, false
+	}
+	if ip4 := ip.To4(); ip4 != nil && !strings.Contains(addr, ":") {
+		return ip4, true
+	}
+	return ip.To16(), true
+}
+
+// Size - number of addresses in the range, capped at the maximum uint64.
+func (r AddressRange) Size() uint64 {
+	size := new(big.Int).Sub(
+		new(big.Int).SetBytes(r.End), new(big.Int).SetBytes(r.Start))
+	size.Add(size, big.NewInt(1))
+	if !size.IsUint64() {
+		return math.MaxUint64
+	}
+	return size.Uint64()
+}
+
+// Overlaps - true if the two ranges share any address.
+func (r AddressRange) Overlaps(other AddressRange) bool {
+	if len(r.Start) != len(other.Start) {
+		return false
+	}
+	return bytes.Compare(r.Start, other.End) <= 0 &&
+		bytes.Compare(other.Start, r.End) <= 0
+}
+
+// AnyRangesOverlap - true if any two of the ranges share an address.  The
+// ranges are sorted by family and start address, after which any overlap
+// must include a pair of neighbouring ranges, so there's no need to compare
+// every range with every other.
+func AnyRangesOverlap(ranges []AddressRange) bool {
+	sorted := append([]AddressRange(nil), ranges...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if len(sorted[i].Start) != len(sorted[j].Start) {
+			return len(sorted[i].Start) < len(sorted[j].Start)
+		}
+		return bytes.Compare(sorted[i].Start, sorted[j].Start) < 0
+	})
+
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i-1].Overlaps(sorted[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Contains - true if every address in the other range is in this range.
+func (r AddressRange) Contains(other AddressRange) bool {
+	if len(r.Start) != len(other.Start) {
+		return false
+	}
+	return bytes.Compare(r.Start, other.Start) <= 0 &&
+		bytes.Compare(other.End, r.End) <= 0
+}
+
+// ParseAddressPrefixOrRange - as ParseAddressRange, but also accepts an
+// address prefix (eg 10.0.0.0/8), which is converted to the range of
+// addresses it covers.
+func ParseAddressPrefixOrRange(addr string) (AddressRange, bool) {
+	if !strings.Contains(addr, "/") {
+		return ParseAddressRange(addr)
+	}
+
+	_, prefix, err := net.ParseCIDR(addr)
+	if err != nil {
+		return AddressRange{}, false
+	}
+	start := prefix.IP
+	end := make(net.IP, len(start))
+	for i := range start {
+		end[i] = start[i] | ^prefix.Mask[i]
+	}
+	return AddressRange{Start: start, End: end}, true
+}
+
+// IsValidAddressOrPrefix - true if the string is an IPv4 / IPv6 address,
+// an address prefix (eg 10.0.0.0/8), or an address range (eg
+// 10.0.0.1-10.0.0.9).
+func IsValidAddressOrPrefix(addr string) bool {
+	_, ok := ParseAddressPrefixOrRange(addr)
+	return ok
+}
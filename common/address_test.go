@@ -0,0 +1,203 @@
+// Copyright (c) 2021, AT&T Intellectual Property. All rights reserved.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package common
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseAddressRange(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		addr    string
+		expOk   bool
+		expSize uint64
+	}{
+		{"Single IPv4", "10.0.0.1", true, 1},
+		{"IPv4 range", "10.0.0.1-10.0.0.10", true, 10},
+		{"IPv4 range across octet", "10.0.0.250-10.0.1.5", true, 12},
+		{"Single IPv6", "2001:db8::1", true, 1},
+		{"IPv6 range", "2001:db8::1-2001:db8::100", true, 256},
+		{"Full IPv6 range", "::-ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff",
+			true, math.MaxUint64},
+		{"Reversed range", "10.0.0.10-10.0.0.1", false, 0},
+		{"Mixed families", "10.0.0.1-2001:db8::1", false, 0},
+		{"Too many parts", "10.0.0.1-10.0.0.2-10.0.0.3", false, 0},
+		{"Invalid address", "10.0.0.256", false, 0},
+		{"Empty", "", false, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			addrRange, ok := ParseAddressRange(test.addr)
+			if ok != test.expOk {
+				t.Fatalf("Unexpected result for %s: exp %t, got %t\n",
+					test.name, test.expOk, ok)
+			}
+			if ok && addrRange.Size() != test.expSize {
+				t.Fatalf("Unexpected size for %s: exp %d, got %d\n",
+					test.name, test.expSize, addrRange.Size())
+			}
+		})
+	}
+}
+
+func TestAddressRangeOverlaps(t *testing.T) {
+
+	tests := []struct {
+		name       string
+		r1, r2     string
+		expOverlap bool
+	}{
+		{"Disjoint", "10.0.0.1-10.0.0.5", "10.0.0.6-10.0.0.9", false},
+		{"Touching", "10.0.0.1-10.0.0.5", "10.0.0.5-10.0.0.9", true},
+		{"Contained", "10.0.0.1-10.0.0.9", "10.0.0.3", true},
+		{"Different families", "0.0.0.0-255.255.255.255", "::1", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r1, _ := ParseAddressRange(test.r1)
+			r2, _ := ParseAddressRange(test.r2)
+			if r1.Overlaps(r2) != test.expOverlap ||
+				r2.Overlaps(r1) != test.expOverlap {
+				t.Fatalf("Unexpected result for %s: exp %t\n",
+					test.name, test.expOverlap)
+			}
+		})
+	}
+}
+
+func TestAnyRangesOverlap(t *testing.T) {
+
+	tests := []struct {
+		name       string
+		ranges     []string
+		expOverlap bool
+	}{
+		{"None", []string{}, false},
+		{"Single", []string{"10.0.0.1-10.0.0.5"}, false},
+		{"Disjoint, unsorted",
+			[]string{"10.0.0.20", "10.0.0.1-10.0.0.5", "10.0.0.6-10.0.0.9"},
+			false},
+		{"Touching, unsorted",
+			[]string{"10.0.0.6-10.0.0.9", "10.0.0.1-10.0.0.6"}, true},
+		{"Contained in earlier range",
+			[]string{"10.0.0.1-10.0.0.99", "10.0.0.10", "10.0.0.50"}, true},
+		{"Different families",
+			[]string{"0.0.0.0-255.255.255.255", "::1", "::2-::9"}, false},
+		{"Overlap after family change",
+			[]string{"10.0.0.1", "::1-::9", "::5"}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var ranges []AddressRange
+			for _, str := range test.ranges {
+				r, ok := ParseAddressRange(str)
+				if !ok {
+					t.Fatalf("Invalid range %s\n", str)
+				}
+				ranges = append(ranges, r)
+			}
+			if AnyRangesOverlap(ranges) != test.expOverlap {
+				t.Fatalf("Unexpected result for %s: exp %t\n",
+					test.name, test.expOverlap)
+			}
+		})
+	}
+}
+
+func TestAddressRangeContains(t *testing.T) {
+
+	tests := []struct {
+		name        string
+		r1, r2      string
+		expContains bool
+	}{
+		{"Inside", "10.0.0.0/24", "10.0.0.10-10.0.0.20", true},
+		{"Identical", "10.0.0.0/24", "10.0.0.0-10.0.0.255", true},
+		{"Overlapping start", "10.0.0.0/24", "9.255.255.255-10.0.0.1", false},
+		{"Overlapping end", "10.0.0.0/24", "10.0.0.250-10.0.1.1", false},
+		{"Outside", "10.0.0.0/24", "10.0.1.1", false},
+		{"Different families", "0.0.0.0/0", "::1", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r1, _ := ParseAddressPrefixOrRange(test.r1)
+			r2, _ := ParseAddressPrefixOrRange(test.r2)
+			if r1.Contains(r2) != test.expContains {
+				t.Fatalf("Unexpected result for %s: exp %t\n",
+					test.name, test.expContains)
+			}
+		})
+	}
+}
+
+func TestParseAddressPrefixOrRange(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		addr     string
+		expOk    bool
+		expRange string
+	}{
+		{"IPv4 prefix", "10.0.1.0/24", true, "10.0.1.0-10.0.1.255"},
+		{"IPv4 prefix with host bits", "10.0.1.7/30", true,
+			"10.0.1.4-10.0.1.7"},
+		{"IPv4 host prefix", "10.0.1.7/32", true, "10.0.1.7"},
+		{"IPv6 prefix", "2001:db8::/120", true,
+			"2001:db8::-2001:db8::ff"},
+		{"Range", "10.0.0.1-10.0.0.9", true, "10.0.0.1-10.0.0.9"},
+		{"Bad prefix", "10.0.1.0/40", false, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actRange, ok := ParseAddressPrefixOrRange(test.addr)
+			if ok != test.expOk {
+				t.Fatalf("Unexpected result for %s: exp %t, got %t\n",
+					test.name, test.expOk, ok)
+			}
+			if !ok {
+				return
+			}
+			expRange, _ := ParseAddressRange(test.expRange)
+			if !actRange.Start.Equal(expRange.Start) ||
+				!actRange.End.Equal(expRange.End) {
+				t.Fatalf("Unexpected range for %s: exp %v, got %v\n",
+					test.name, expRange, actRange)
+			}
+		})
+	}
+}
+
+func TestIsValidAddressOrPrefix(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		addr     string
+		expValid bool
+	}{
+		{"Address", "192.0.2.1", true},
+		{"Prefix", "192.0.2.0/24", true},
+		{"IPv6 prefix", "2001:db8::/32", true},
+		{"Range", "192.0.2.1-192.0.2.9", true},
+		{"Bad prefix length", "192.0.2.0/33", false},
+		{"Name", "servers", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if IsValidAddressOrPrefix(test.addr) != test.expValid {
+				t.Fatalf("Unexpected result for %s: exp %t\n",
+					test.name, test.expValid)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2021, AT&T Intellectual Property Inc.
+// All rights reserved.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package common
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Bandwidth units, in bit/s.  As for tc, 'bps' suffixes are in bytes/s.
+// Checked in order, so 'bps' and 'bit' must come after the prefixed units.
+var bandwidthUnits = []struct {
+	suffix     string
+	multiplier float64
+}{
+	{"kbit", 1e3},
+	{"mbit", 1e6},
+	{"gbit", 1e9},
+	{"tbit", 1e12},
+	{"bit", 1},
+	{"kbps", 8e3},
+	{"mbps", 8e6},
+	{"gbps", 8e9},
+	{"tbps", 8e12},
+	{"bps", 8},
+}
+
+// Bandwidth - a QoS bandwidth setting, either an absolute rate in bit/s or
+// a percentage of the parent's rate.
+type Bandwidth struct {
+	bps       uint64
+	percent   float64
+	isPercent bool
+}
+
+// ParseBandwidth - convert a bandwidth string into a Bandwidth.  Accepts a
+// number followed by a unit (bit, Kbit, Mbit, Gbit, Tbit, or bps, Kbps,
+// Mbps, Gbps, Tbps for bytes/s, in any case), a plain number (bit/s), or a
+// percentage eg "50%".  Percentages must not
+// exceed 100.
+func ParseBandwidth(bandwidth string) (Bandwidth, bool) {
+	bandwidth = strings.ToLower(strings.TrimSpace(bandwidth))
+
+	if strings.HasSuffix(bandwidth, "%") {
+		percent, ok := parsePositiveNumber(
+			strings.TrimSuffix(bandwidth, "%"))
+		if !ok || percent > 100 {
+			return Bandwidth{}, false
+		}
+		return Bandwidth{percent: percent, isPercent: true}, true
+	}
+
+	multiplier := float64(1)
+	for _, unit := range bandwidthUnits {
+		if strings.HasSuffix(bandwidth, unit.suffix) {
+			multiplier = unit.multiplier
+			bandwidth = strings.TrimSuffix(bandwidth, unit.suffix)
+			break
+		}
+	}
+	value, ok := parsePositiveNumber(bandwidth)
+	if !ok {
+		return Bandwidth{}, false
+	}
+	return Bandwidth{bps: uint64(math.Round(value * multiplier))}, true
+}
+
+// parsePositiveNumber - parse a decimal number greater than zero, without
+// the exponents, hex and so on that ParseFloat also accepts.
+func parsePositiveNumber(number string) (float64, bool) {
+	if number == "" || strings.Trim(number, "0123456789.") != "" {
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil || value <= 0 {
+		return 0, false
+	}
+	return value, true
+}
+
+// IsPercent - true if bandwidth is a percentage of its parent's rate.
+func (b Bandwidth) IsPercent() bool {
+	return b.isPercent
+}
+
+// Resolve - return the rate in bit/s, given the parent's rate in bit/s.
+// The parent rate is only used for percentages.
+func (b Bandwidth) Resolve(parentBps uint64) uint64 {
+	if !b.isPercent {
+		return b.bps
+	}
+	return uint64(math.Round(float64(parentBps) * b.percent / 100))
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2021, AT&T Intellectual Property. All rights reserved.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package common
+
+import (
+	"testing"
+)
+
+func TestParseBandwidth(t *testing.T) {
+
+	const parentBps = 2e9
+
+	tests := []struct {
+		name       string
+		bandwidth  string
+		expOk      bool
+		expPercent bool
+		expBps     uint64
+	}{
+		{"bit/s", "500bit", true, false, 500},
+		{"Plain number", "500", true, false, 500},
+		{"Kbit/s", "64Kbit", true, false, 64000},
+		{"Mbit/s", "100Mbit", true, false, 100000000},
+		{"Gbit/s, lower case", "1gbit", true, false, 1000000000},
+		{"Fractional Gbit/s", "2.5Gbit", true, false, 2500000000},
+		{"Tbit/s", "1Tbit", true, false, 1000000000000},
+		{"bytes/s", "500bps", true, false, 4000},
+		{"Kbytes/s", "64Kbps", true, false, 512000},
+		{"Mbytes/s", "100Mbps", true, false, 800000000},
+		{"Gbytes/s, lower case", "1gbps", true, false, 8000000000},
+		{"Tbytes/s", "1Tbps", true, false, 8000000000000},
+		{"Fractional Mbytes/s", "1.5MBPS", true, false, 12000000},
+		{"Percentage", "50%", true, true, 1000000000},
+		{"Fractional percentage", "12.5%", true, true, 250000000},
+		{"Full percentage", "100%", true, true, 2000000000},
+		{"Percentage too high", "101%", false, false, 0},
+		{"Zero", "0Mbit", false, false, 0},
+		{"Unknown unit", "10Mbyte", false, false, 0},
+		{"Unit only", "Mbit", false, false, 0},
+		{"Empty", "", false, false, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			bandwidth, ok := ParseBandwidth(test.bandwidth)
+			if ok != test.expOk {
+				t.Fatalf("Unexpected parse result for %q: exp %t, got %t\n",
+					test.bandwidth, test.expOk, ok)
+			}
+			if !ok {
+				return
+			}
+			if bandwidth.IsPercent() != test.expPercent {
+				t.Fatalf("Unexpected percent for %q: exp %t, got %t\n",
+					test.bandwidth, test.expPercent, bandwidth.IsPercent())
+			}
+			if act := bandwidth.Resolve(parentBps); act != test.expBps {
+				t.Fatalf("Unexpected rate for %q: exp %d, got %d\n",
+					test.bandwidth, test.expBps, act)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2021, AT&T Intellectual Property Inc.
+// All rights reserved.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package common
+
+import (
+	"strconv"
+	"strings"
+)
+
+const (
+	MinDscpValue  = 0
+	MaxDscpValue  = 63
+	NumDscpValues = MaxDscpValue + 1
+)
+
+// DSCP names, as accepted by the dscp-name-or-range type, and their values.
+var dscpNames = map[string]int{
+	"default": 0,
+	"cs0":     0,
+	"cs1":     8,
+	"af11":    10,
+	"af12":    12,
+	"af13":    14,
+	"cs2":     16,
+	"af21":    18,
+	"af22":    20,
+	"af23":    22,
+	"cs3":     24,
+	"af31":    26,
+	"af32":    28,
+	"af33":    30,
+	"cs4":     32,
+	"af41":    34,
+	"af42":    36,
+	"af43":    38,
+	"cs5":     40,
+	"va":      44,
+	"ef":      46,
+	"cs6":     48,
+	"cs7":     56,
+}
+
+// ParseDscpValue - convert a single DSCP value, either numeric or a name
+// such as 'af11', into its numeric value.
+func ParseDscpValue(dscp string) (int, bool) {
+	dscp = strings.ToLower(strings.TrimSpace(dscp))
+	if value, ok := dscpNames[dscp]; ok {
+		return value, true
+	}
+	value, err := strconv.Atoi(dscp)
+	if err != nil || value < MinDscpValue || value > MaxDscpValue {
+		return 0, false
+	}
+	return value, true
+}
+
+// ParseDscpValues - convert a DSCP value, name or range (eg "0-7" or
+// "af11-af13") into the list of numeric values it covers.
+func ParseDscpValues(dscp string) ([]int, bool) {
+	bounds := strings.Split(dscp, "-")
+	switch len(bounds) {
+	case 1:
+		value, ok := ParseDscpValue(bounds[0])
+		if !ok {
+			return nil, false
+		}
+		return []int{value}, true
+	case 2:
+		low, ok := ParseDscpValue(bounds[0])
+		if !ok {
+			return nil, false
+		}
+		high, ok := ParseDscpValue(bounds[1])
+		if !ok || high < low {
+			return nil, false
+		}
+		values := make([]int, 0, high-low+1)
+		for value := low; value <= high; value++ {
+			values = append(values, value)
+		}
+		return values, true
+	}
+	return nil, false
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2021, AT&T Intellectual Property. All rights reserved.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package common
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDscpValues(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		dscp      string
+		expOk     bool
+		expValues []int
+	}{
+		{"Number", "46", true, []int{46}},
+		{"Lowest number", "0", true, []int{0}},
+		{"Highest number", "63", true, []int{63}},
+		{"Name", "af11", true, []int{10}},
+		{"Name, upper case", "EF", true, []int{46}},
+		{"Numeric range", "0-3", true, []int{0, 1, 2, 3}},
+		{"Named range", "af11-af12", true, []int{10, 11, 12}},
+		{"Single value range", "7-7", true, []int{7}},
+		{"Number too high", "64", false, nil},
+		{"Negative number", "-1", false, nil},
+		{"Unknown name", "af99", false, nil},
+		{"Reversed range", "7-0", false, nil},
+		{"Range with too many parts", "0-3-7", false, nil},
+		{"Empty", "", false, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			values, ok := ParseDscpValues(test.dscp)
+			if ok != test.expOk {
+				t.Fatalf("Unexpected parse result for %q: exp %t, got %t\n",
+					test.dscp, test.expOk, ok)
+			}
+			if !reflect.DeepEqual(values, test.expValues) {
+				t.Fatalf("Unexpected values for %q: exp %v, got %v\n",
+					test.dscp, test.expValues, values)
+			}
+		})
+	}
+}
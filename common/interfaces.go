@@ -0,0 +1,146 @@
+// Copyright (c) 2019-2021, AT&T Intellectual Property Inc.
+// All rights reserved.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package common
+
+import (
+	"strings"
+
+	"github.com/danos/yang/xpath/xutils"
+)
+
+// interfaceFilters - filters used to find interface nodes, either in
+// config only or in config and state.
+type interfaceFilters struct {
+	interfaces xutils.XFilter
+	allTypes   xutils.XFilter
+	vif        xutils.XFilter
+}
+
+var configIntfFilters = interfaceFilters{
+	interfaces: GetFilter("interfaces"),
+	allTypes:   GetFilter("*"),
+	vif:        GetFilter("vif"),
+}
+
+var stateIntfFilters = interfaceFilters{
+	interfaces: GetConfigAndStateFilter("interfaces"),
+	allTypes:   GetConfigAndStateFilter("*"),
+	vif:        GetConfigAndStateFilter("vif"),
+}
+
+// ParseInterfaceName - split an interface name into base interface name
+// and, for VIFs (eg dp0s1.10), VIF ID.  The boolean return value is true
+// for VIFs.
+func ParseInterfaceName(intfName string) (string, bool, string) {
+
+	intfParts := strings.Split(intfName, ".")
+	intfVal := intfParts[0]
+
+	vifVal := ""
+	if len(intfParts) == 2 {
+		vifVal = intfParts[1]
+		return intfVal, true, vifVal
+	}
+
+	return intfVal, false, "not-VIF-interface"
+}
+
+// GetInterfaceName - return the name used to reference an interface or VIF
+// list entry (eg dp0s1 or dp0s1.10), as resolved by GetInterfaceNode.
+func GetInterfaceName(intfNode xutils.XpathNode) string {
+	if intfNode.XName() == "vif" {
+		return intfNode.XParent().XValue() + "." + intfNode.XValue()
+	}
+	return intfNode.XValue()
+}
+
+// GetInterfaceNode - find the interface list entry, of any interface type,
+// or the VIF list entry for the interface name provided.
+//
+// Base interfaces whose type (eg 'switch') is in ignoreTypes are skipped,
+// but VIFs on those interfaces are not.
+func GetInterfaceNode(
+	root xutils.XpathNode,
+	intfName string,
+	ignoreTypes []string,
+) (xutils.XpathNode, bool) {
+	return getInterfaceNode(root, intfName, ignoreTypes, configIntfFilters)
+}
+
+// GetInterfaceNodeInState - as GetInterfaceNode, but also finds interfaces
+// and VIFs that are only present in state, eg discovered but unconfigured
+// dataplane interfaces.
+func GetInterfaceNodeInState(
+	root xutils.XpathNode,
+	intfName string,
+	ignoreTypes []string,
+) (xutils.XpathNode, bool) {
+	return getInterfaceNode(root, intfName, ignoreTypes, stateIntfFilters)
+}
+
+func getInterfaceNode(
+	root xutils.XpathNode,
+	intfName string,
+	ignoreTypes []string,
+	filters interfaceFilters,
+) (xutils.XpathNode, bool) {
+
+	intfVal, isVIF, vifVal := ParseInterfaceName(intfName)
+
+	intfNodes := root.XChildren(filters.interfaces, xutils.Sorted)
+	if len(intfNodes) != 1 {
+		return nil, false
+	}
+
+	// Now get all interface list entries (we get one big list with all
+	// interface list entries, XValue() == key).  This means we don't need
+	// to care about tagnode / ifname etc.
+	intfListEntries := intfNodes[0].XChildren(filters.allTypes, xutils.Sorted)
+	if intfListEntries == nil {
+		return nil, false
+	}
+
+	for _, intf := range intfListEntries {
+		if intf.XValue() != intfVal {
+			// Base interface name doesn't match. Next!
+			continue
+		}
+		if !isVIF {
+			if isIgnoredInterfaceType(intf.XName(), ignoreTypes) {
+				// Used to ignore specific interface types (but not VIFs
+				// on these interfaces).  Typical use is to remove L2
+				// interfaces (eg switch and backplane).
+				continue
+			}
+
+			// Interface base name matches, not looking for VIF. Pass.
+			return intf, true
+		}
+
+		vifs := intf.XChildren(filters.vif, xutils.Sorted)
+		for _, vif := range vifs {
+			if vif.XValue() == vifVal {
+				// Base interface and VIF both match. Pass.
+				return vif, true
+			}
+		}
+
+		// Matched on base interface name, so if no matching VIF, we're done.
+		return nil, false
+	}
+
+	return nil, false
+}
+
+func isIgnoredInterfaceType(intfType string, ignoreTypes []string) bool {
+	for _, name := range ignoreTypes {
+		if intfType == name {
+			return true
+		}
+	}
+
+	return false
+}
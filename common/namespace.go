@@ -0,0 +1,88 @@
+// Copyright (c) 2021, AT&T Intellectual Property Inc.
+// All rights reserved.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package common
+
+import (
+	"encoding/xml"
+	"strings"
+
+	"github.com/danos/yang/xpath/xutils"
+)
+
+// Namespaces of the YANG modules whose nodes are referenced by plugins.
+const (
+	DataplaneNamespace  = "urn:vyatta.com:mgmt:vyatta-interfaces-dataplane:1"
+	InterfacesNamespace = "urn:vyatta.com:mgmt:vyatta-interfaces:1"
+	PolicyNamespace     = "urn:vyatta.com:mgmt:vyatta-policy:1"
+	QosNamespace        = "urn:vyatta.com:mgmt:vyatta-policy-qos:1"
+	RoutingNamespace    = "urn:vyatta.com:mgmt:vyatta-routing:1"
+)
+
+// prefixNamespaces maps the prefixes used in the original must statements
+// onto the namespace of the module each refers to.
+var prefixNamespaces = map[string]string{
+	"dp":      DataplaneNamespace,
+	"if":      InterfacesNamespace,
+	"policy":  PolicyNamespace,
+	"qos":     QosNamespace,
+	"routing": RoutingNamespace,
+}
+
+// GetNamespace - return the namespace for the given module prefix, or
+// false if the prefix is not known.
+func GetNamespace(prefix string) (string, bool) {
+	namespace, ok := prefixNamespaces[prefix]
+	return namespace, ok
+}
+
+// GetFilterWithNamespace - as GetFilter, but only matches nodes with the
+// given name in the given namespace, so identically named nodes from other
+// modules are ignored.  An empty namespace matches any module.
+func GetFilterWithNamespace(namespace, name string) xutils.XFilter {
+	return xutils.NewXFilterConfigOnly(xml.Name{Space: namespace, Local: name})
+}
+
+// GetConfigAndStateFilterWithNamespace - as GetFilterWithNamespace, but
+// also matches state nodes.
+func GetConfigAndStateFilterWithNamespace(
+	namespace, name string,
+) xutils.XFilter {
+	return xutils.NewXFilterConfigAndState(
+		xml.Name{Space: namespace, Local: name})
+}
+
+// GetPrefixedFilter - return a filter for a node name written as it would
+// be in a must statement, eg 'qos:profile'.  Names without a prefix match
+// any module, as with GetFilter.
+//
+// Filters are created once, when plugins are loaded, from fixed strings, so
+// an unknown prefix is a programming error and causes a panic.
+func GetPrefixedFilter(prefixedName string) xutils.XFilter {
+	return GetFilterWithNamespace(getPrefixedName(prefixedName))
+}
+
+// GetPrefixedConfigAndStateFilter - as GetPrefixedFilter, but also matches
+// state nodes.
+func GetPrefixedConfigAndStateFilter(prefixedName string) xutils.XFilter {
+	return GetConfigAndStateFilterWithNamespace(getPrefixedName(prefixedName))
+}
+
+// getPrefixedName - split a prefixed node name into namespace and local
+// name, panicking if the prefix is not known.
+func getPrefixedName(prefixedName string) (string, string) {
+	i := strings.Index(prefixedName, ":")
+	if i < 0 {
+		return "", prefixedName
+	}
+
+	prefix, name := prefixedName[:i], prefixedName[i+1:]
+	namespace, ok := GetNamespace(prefix)
+	if !ok {
+		panic("Unknown module prefix '" + prefix + "' in '" +
+			prefixedName + "'")
+	}
+	return namespace, name
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2021, AT&T Intellectual Property. All rights reserved.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package common
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/danos/yang/xpath/xutils"
+)
+
+func TestGetPrefixedFilter(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		filter   string
+		expSpace string
+		expLocal string
+	}{
+		{
+			name:     "No prefix",
+			filter:   "profile",
+			expSpace: "",
+			expLocal: "profile",
+		},
+		{
+			name:     "QoS prefix",
+			filter:   "qos:profile",
+			expSpace: QosNamespace,
+			expLocal: "profile",
+		},
+		{
+			name:     "Dataplane prefix",
+			filter:   "dp:speed",
+			expSpace: DataplaneNamespace,
+			expLocal: "speed",
+		},
+		{
+			name:     "Routing prefix",
+			filter:   "routing:routing-instance",
+			expSpace: RoutingNamespace,
+			expLocal: "routing-instance",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actFilter := GetPrefixedFilter(test.filter)
+			expFilter := xutils.NewXFilterConfigOnly(
+				xml.Name{Space: test.expSpace, Local: test.expLocal})
+			if actFilter != expFilter {
+				t.Fatalf("Unexpected filter for %s: exp %v, got %v\n",
+					test.name, expFilter, actFilter)
+			}
+		})
+	}
+}
+
+func TestGetPrefixedConfigAndStateFilter(t *testing.T) {
+
+	actFilter := GetPrefixedConfigAndStateFilter("dp:speed")
+	expFilter := xutils.NewXFilterConfigAndState(
+		xml.Name{Space: DataplaneNamespace, Local: "speed"})
+	if actFilter != expFilter {
+		t.Fatalf("Unexpected filter: exp %v, got %v\n", expFilter, actFilter)
+	}
+
+	actFilter = GetPrefixedConfigAndStateFilter("speed")
+	expFilter = GetConfigAndStateFilter("speed")
+	if actFilter != expFilter {
+		t.Fatalf("Unexpected unprefixed filter: exp %v, got %v\n",
+			expFilter, actFilter)
+	}
+}
+
+func TestGetPrefixedFilterUnknownPrefix(t *testing.T) {
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("Expected panic for unknown prefix\n")
+		}
+	}()
+	GetPrefixedFilter("unknown:name")
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2021, AT&T Intellectual Property Inc.
+// All rights reserved.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package common
+
+import (
+	"strconv"
+	"strings"
+)
+
+const (
+	MinPort = 1
+	MaxPort = 65535
+)
+
+// ParsePortRange - convert a port number, or a range of the form
+// "<start>-<end>", into its inclusive start and end values.  Returns false
+// if either value is not a valid port number or the start is above the end.
+func ParsePortRange(portRange string) (uint32, uint32, bool) {
+	parts := strings.Split(portRange, "-")
+	if len(parts) > 2 {
+		return 0, 0, false
+	}
+
+	start, ok := parsePort(parts[0])
+	if !ok {
+		return 0, 0, false
+	}
+	end := start
+	if len(parts) == 2 {
+		if end, ok = parsePort(parts[1]); !ok {
+			return 0, 0, false
+		}
+	}
+
+	if start > end {
+		return 0, 0, false
+	}
+	return start, end, true
+}
+
+func parsePort(port string) (uint32, bool) {
+	value, err := strconv.ParseUint(port, 10, 32)
+	if err != nil || value < MinPort || value > MaxPort {
+		return 0, false
+	}
+	return uint32(value), true
+}
+
+// IsValidPortName - true if the string could be a service name, as found in
+// /etc/services, ie it contains only letters, digits, '-' and '_', does not
+// start with '-', and has at least one letter.  Whether the service is
+// actually known is left to the dataplane.
+func IsValidPortName(name string) bool {
+	hasLetter := false
+	for i, c := range name {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+			hasLetter = true
+		case c >= '0' && c <= '9', c == '_':
+		case c == '-' && i > 0:
+		default:
+			return false
+		}
+	}
+	return hasLetter
+}
+
+// IsValidPortOrName - true if the string is a port number, port range or
+// service name.
+func IsValidPortOrName(port string) bool {
+	if _, _, ok := ParsePortRange(port); ok {
+		return true
+	}
+	return IsValidPortName(port)
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2021, AT&T Intellectual Property. All rights reserved.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package common
+
+import (
+	"testing"
+)
+
+func TestParsePortRange(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		port     string
+		expOk    bool
+		expStart uint32
+		expEnd   uint32
+	}{
+		{"Single port", "80", true, 80, 80},
+		{"Range", "1024-65535", true, 1024, 65535},
+		{"Zero", "0", false, 0, 0},
+		{"Too large", "65536", false, 0, 0},
+		{"Reversed range", "90-80", false, 0, 0},
+		{"Open range", "80-", false, 0, 0},
+		{"Name", "http", false, 0, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			start, end, ok := ParsePortRange(test.port)
+			if ok != test.expOk || start != test.expStart ||
+				end != test.expEnd {
+				t.Fatalf("Unexpected result for %s: exp %t %d-%d, "+
+					"got %t %d-%d\n", test.name,
+					test.expOk, test.expStart, test.expEnd, ok, start, end)
+			}
+		})
+	}
+}
+
+func TestIsValidPortOrName(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		port     string
+		expValid bool
+	}{
+		{"Port", "443", true},
+		{"Range", "8000-8080", true},
+		{"Name", "https", true},
+		{"Name with dash", "http-alt", true},
+		{"Leading digit", "3com-tsmux", true},
+		{"Leading dash", "-http", false},
+		{"Bad character", "ht.tp", false},
+		{"Out of range", "70000", false},
+		{"Empty", "", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if IsValidPortOrName(test.port) != test.expValid {
+				t.Fatalf("Unexpected result for %s: exp %t\n",
+					test.name, test.expValid)
+			}
+		})
+	}
+}
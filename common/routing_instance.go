@@ -0,0 +1,44 @@
+// Copyright (c) 2021, AT&T Intellectual Property Inc.
+// All rights reserved.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package common
+
+import (
+	"github.com/danos/yang/xpath/xutils"
+)
+
+// DefaultRoutingInstance - routing instance of any interface that is not
+// explicitly bound to one.
+const DefaultRoutingInstance = "default"
+
+// Filters used to find routing instance nodes, all defined by the routing
+// module.
+var routingFilter = GetPrefixedFilter("routing:routing")
+var routingInstanceFilter = GetPrefixedFilter("routing:routing-instance")
+var riInterfaceFilter = GetPrefixedFilter("routing:interface")
+
+// GetInterfaceRoutingInstance - return the name of the routing instance
+// (VRF) the named interface or VIF is bound to.  Interfaces are bound by
+// being listed under /routing/routing-instance/interface; those not listed
+// are in the default routing instance.
+//
+// VIFs are bound independently of their parent interface, so a VIF may be
+// in a different routing instance to its parent.
+func GetInterfaceRoutingInstance(
+	root xutils.XpathNode,
+	intfName string,
+) string {
+	instanceNodes := GetDescendantNodesFromSingleNode(
+		root, []xutils.XFilter{routingFilter, routingInstanceFilter})
+	for _, instanceNode := range instanceNodes {
+		for _, intfNode := range instanceNode.XChildren(
+			riInterfaceFilter, xutils.Unsorted) {
+			if intfNode.XValue() == intfName {
+				return instanceNode.XValue()
+			}
+		}
+	}
+	return DefaultRoutingInstance
+}
@@ -0,0 +1,42 @@
+// Copyright (c) 2021, AT&T Intellectual Property. All rights reserved.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package common
+
+import (
+	"testing"
+
+	"github.com/danos/yang/xpath/xpathtest"
+	"github.com/danos/yang/xpath/xutils"
+)
+
+func TestGetInterfaceRoutingInstance(t *testing.T) {
+
+	testTree := xpathtest.CreateTree(t, []xutils.PathType{
+		{"routing", "routing-instance/instance-name+blue",
+			"interface/name+dp0s1"},
+		{"routing", "routing-instance/instance-name+red",
+			"interface/name+dp0s1.10"},
+	})
+
+	tests := []struct {
+		intf        string
+		expInstance string
+	}{
+		{"dp0s1", "blue"},
+		{"dp0s1.10", "red"},
+		{"dp0s1.20", DefaultRoutingInstance},
+		{"dp0s2", DefaultRoutingInstance},
+	}
+
+	for _, test := range tests {
+		t.Run(test.intf, func(t *testing.T) {
+			actInstance := GetInterfaceRoutingInstance(testTree, test.intf)
+			if actInstance != test.expInstance {
+				t.Fatalf("Unexpected result for %s: exp %s, got %s\n",
+					test.intf, test.expInstance, actInstance)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2021, AT&T Intellectual Property Inc.
+// All rights reserved.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package common
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	speedAutoName = "auto"
+	mbpsPerGbps   = 1000
+)
+
+// Speed - canonical form of an interface speed setting.  Fixed speeds are
+// held in Mbit/s; 'auto' is held separately as it has no fixed value.
+//
+// For ordering purposes 'auto' sorts above all fixed speeds, on the basis
+// that an auto-negotiated link will come up at the highest speed it can.
+// This matches the existing treatment of 'auto' as always acceptable.
+type Speed struct {
+	mbps uint64
+	auto bool
+}
+
+// SpeedAuto - the 'auto' speed setting.
+var SpeedAuto = Speed{auto: true}
+
+// NewSpeed - create a fixed speed from a value in Mbit/s.
+func NewSpeed(mbps uint64) Speed {
+	return Speed{mbps: mbps}
+}
+
+// ParseSpeed - convert a speed string into a Speed.  Accepts 'auto', plain
+// numbers (Mbit/s), and numbers with an 'm' (Mbit/s) or 'g' (Gbit/s) suffix
+// in either case, eg "100m", "10000", "10G", "2.5g".  Returns false if the
+// string cannot be parsed or does not resolve to a whole number of Mbit/s.
+func ParseSpeed(speed string) (Speed, bool) {
+	speed = strings.ToLower(strings.TrimSpace(speed))
+	if speed == speedAutoName {
+		return SpeedAuto, true
+	}
+
+	multiplier := float64(1)
+	switch {
+	case strings.HasSuffix(speed, "g"):
+		multiplier = mbpsPerGbps
+		speed = strings.TrimSuffix(speed, "g")
+	case strings.HasSuffix(speed, "m"):
+		speed = strings.TrimSuffix(speed, "m")
+	}
+
+	// ParseFloat is rather more liberal than we want (exponents, hex, inf
+	// and so on), so restrict ourselves to digits and a decimal point.
+	if speed == "" || strings.Trim(speed, "0123456789.") != "" {
+		return Speed{}, false
+	}
+	value, err := strconv.ParseFloat(speed, 64)
+	if err != nil {
+		return Speed{}, false
+	}
+
+	mbps := value * multiplier
+	if mbps <= 0 || mbps != math.Trunc(mbps) {
+		return Speed{}, false
+	}
+	return NewSpeed(uint64(mbps)), true
+}
+
+// IsAuto - true if speed is 'auto'.
+func (s Speed) IsAuto() bool {
+	return s.auto
+}
+
+// Mbps - fixed speed in Mbit/s.  Zero for 'auto'.
+func (s Speed) Mbps() uint64 {
+	if s.auto {
+		return 0
+	}
+	return s.mbps
+}
+
+// Compare - returns -1, 0 or 1 if s is less than, equal to, or greater
+// than other.
+func (s Speed) Compare(other Speed) int {
+	switch {
+	case s.auto && other.auto:
+		return 0
+	case s.auto:
+		return 1
+	case other.auto:
+		return -1
+	case s.mbps < other.mbps:
+		return -1
+	case s.mbps > other.mbps:
+		return 1
+	}
+	return 0
+}
+
+// String - canonical string form of speed, eg "auto", "100m", "2.5g", "10g"
+func (s Speed) String() string {
+	if s.auto {
+		return speedAutoName
+	}
+	if s.mbps < mbpsPerGbps {
+		return strconv.FormatUint(s.mbps, 10) + "m"
+	}
+	return strconv.FormatFloat(
+		float64(s.mbps)/mbpsPerGbps, 'f', -1, 64) + "g"
+}
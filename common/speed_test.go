@@ -0,0 +1,91 @@
+// Copyright (c) 2021, AT&T Intellectual Property. All rights reserved.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package common
+
+import (
+	"testing"
+)
+
+func TestParseSpeed(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		speed     string
+		expOk     bool
+		expMbps   uint64
+		expAuto   bool
+		expString string
+	}{
+		{"Auto", "auto", true, 0, true, "auto"},
+		{"Auto, upper case", "AUTO", true, 0, true, "auto"},
+		{"Gbit/s", "10g", true, 10000, false, "10g"},
+		{"Gbit/s, upper case", "10G", true, 10000, false, "10g"},
+		{"Fractional Gbit/s", "2.5g", true, 2500, false, "2.5g"},
+		{"Mbit/s", "100m", true, 100, false, "100m"},
+		{"Plain number", "10000", true, 10000, false, "10g"},
+		{"Plain number, not whole Gbit/s", "1500", true, 1500, false,
+			"1.5g"},
+		{"Empty", "", false, 0, false, ""},
+		{"Suffix only", "g", false, 0, false, ""},
+		{"Unknown suffix", "10t", false, 0, false, ""},
+		{"Zero", "0g", false, 0, false, ""},
+		{"Fractional Mbit/s", "0.5m", false, 0, false, ""},
+		{"Exponent", "1e4", false, 0, false, ""},
+		{"Negative", "-10g", false, 0, false, ""},
+		{"Infinity", "inf", false, 0, false, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			speed, ok := ParseSpeed(test.speed)
+			if ok != test.expOk {
+				t.Fatalf("Unexpected parse result for %q: exp %t, got %t\n",
+					test.speed, test.expOk, ok)
+			}
+			if !ok {
+				return
+			}
+			if speed.Mbps() != test.expMbps {
+				t.Fatalf("Unexpected Mbps for %q: exp %d, got %d\n",
+					test.speed, test.expMbps, speed.Mbps())
+			}
+			if speed.IsAuto() != test.expAuto {
+				t.Fatalf("Unexpected auto for %q: exp %t, got %t\n",
+					test.speed, test.expAuto, speed.IsAuto())
+			}
+			if speed.String() != test.expString {
+				t.Fatalf("Unexpected string for %q: exp %s, got %s\n",
+					test.speed, test.expString, speed.String())
+			}
+		})
+	}
+}
+
+func TestCompareSpeed(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		first  Speed
+		second Speed
+		exp    int
+	}{
+		{"Equal fixed speeds", NewSpeed(10000), NewSpeed(10000), 0},
+		{"Lower fixed speed", NewSpeed(2500), NewSpeed(10000), -1},
+		{"Higher fixed speed", NewSpeed(25000), NewSpeed(10000), 1},
+		{"Both auto", SpeedAuto, SpeedAuto, 0},
+		{"Auto above fixed speed", SpeedAuto, NewSpeed(100000), 1},
+		{"Fixed speed below auto", NewSpeed(100000), SpeedAuto, -1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if act := test.first.Compare(test.second); act != test.exp {
+				t.Fatalf("Unexpected result comparing %s and %s: "+
+					"exp %d, got %d\n",
+					test.first, test.second, test.exp, act)
+			}
+		})
+	}
+}
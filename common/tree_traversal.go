@@ -11,18 +11,27 @@ import (
 	"github.com/danos/yang/xpath/xutils"
 )
 
+var allChildrenFilter = GetFilter("*")
+
 // GetFilter
 func GetFilter(name string) xutils.XFilter {
 	return xutils.NewXFilterConfigOnly(xml.Name{Space: "", Local: name})
 }
 
+// GetConfigAndStateFilter - as GetFilter, but also matches state
+// (operational) nodes, for use where functions need to consult state data
+// such as discovered interfaces.
+func GetConfigAndStateFilter(name string) xutils.XFilter {
+	return xutils.NewXFilterConfigAndState(xml.Name{Space: "", Local: name})
+}
+
 // GetSingleChildValue - return value of child node, if there's only one.
 // Otherwise return false (error).  Wraps logic of getting value of child
 // node where we expect only a single child.
 func GetSingleChildValue(node xutils.XpathNode, filter xutils.XFilter,
 ) (string, bool) {
 	childNodes := node.XChildren(filter, xutils.Unsorted)
-	if childNodes == nil || len(childNodes) > 1 {
+	if len(childNodes) != 1 {
 		return "", false
 	}
 	return childNodes[0].XValue(), true
@@ -63,6 +72,11 @@ func GetDescendantNodes(
 
 // GetCountOfChildNodesWithRequiredValues - return the number of child nodes
 // that match the required set of {name, value} pairs.
+//
+// NB: a node that is missing one of the required children, or has more
+// than one of them, is still counted as a match provided no other required
+// child has the wrong value.  Use GetChildNodeMatchCounts where that is
+// not wanted.
 func GetCountOfChildNodesWithRequiredValues(
 	nodes []xutils.XpathNode,
 	filterValueMap map[xutils.XFilter]string,
@@ -86,3 +100,102 @@ func GetCountOfChildNodesWithRequiredValues(
 	}
 	return count
 }
+
+// GetAllDescendantNodesWithName - return all nodes anywhere below node
+// (not just children) that have the given name.  Use sparingly, as this
+// walks the whole subtree.
+func GetAllDescendantNodesWithName(
+	node xutils.XpathNode,
+	name string,
+) []xutils.XpathNode {
+	var matches []xutils.XpathNode
+	for _, child := range node.XChildren(allChildrenFilter, xutils.Unsorted) {
+		if child.XName() == name {
+			matches = append(matches, child)
+		}
+		matches = append(matches,
+			GetAllDescendantNodesWithName(child, name)...)
+	}
+	return matches
+}
+
+// ChildMatchResult - result of checking a node's children against a set of
+// required {name, value} pairs.  Where several children fail to match, the
+// result is the first failure in the order listed here, so the result does
+// not depend on the order in which children are checked.
+type ChildMatchResult int
+
+const (
+	// ChildMatch - every required child is present once, with the
+	// required value.
+	ChildMatch ChildMatchResult = iota
+	// ChildMissing - at least one required child is not present.
+	ChildMissing
+	// ChildMultiple - at least one required child is present more than
+	// once.
+	ChildMultiple
+	// ChildMismatch - at least one required child has the wrong value.
+	ChildMismatch
+)
+
+// ChildMatchCounts - number of nodes with each ChildMatchResult.
+type ChildMatchCounts struct {
+	Match    int
+	Missing  int
+	Multiple int
+	Mismatch int
+}
+
+// MatchChildValues - check that node has exactly one child for each of the
+// required {name, value} pairs, with the required value.
+func MatchChildValues(
+	node xutils.XpathNode,
+	filterValueMap map[xutils.XFilter]string,
+) ChildMatchResult {
+	missing, multiple, mismatch := false, false, false
+	for filter, value := range filterValueMap {
+		children := node.XChildren(filter, xutils.Unsorted)
+		switch {
+		case len(children) == 0:
+			missing = true
+		case len(children) > 1:
+			multiple = true
+		case children[0].XValue() != value:
+			mismatch = true
+		}
+	}
+
+	switch {
+	case missing:
+		return ChildMissing
+	case multiple:
+		return ChildMultiple
+	case mismatch:
+		return ChildMismatch
+	}
+	return ChildMatch
+}
+
+// GetChildNodeMatchCounts - strict version of
+// GetCountOfChildNodesWithRequiredValues.  Returns the number of nodes that
+// match the required set of {name, value} pairs, and the number that don't
+// because a required child is missing, duplicated, or has the wrong value.
+func GetChildNodeMatchCounts(
+	nodes []xutils.XpathNode,
+	filterValueMap map[xutils.XFilter]string,
+) ChildMatchCounts {
+	var counts ChildMatchCounts
+	for _, node := range nodes {
+		switch MatchChildValues(node, filterValueMap) {
+		case ChildMatch:
+			counts.Match++
+		case ChildMissing:
+			counts.Missing++
+		case ChildMultiple:
+			counts.Multiple++
+		case ChildMismatch:
+			counts.Mismatch++
+		}
+	}
+	return counts
+}
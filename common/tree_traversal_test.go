@@ -0,0 +1,128 @@
+// Copyright (c) 2021, AT&T Intellectual Property. All rights reserved.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package common
+
+import (
+	"testing"
+
+	"github.com/danos/yang/xpath/xpathtest"
+	"github.com/danos/yang/xpath/xutils"
+)
+
+type childMatchTestSpec struct {
+	name           string
+	config         []xutils.PathType
+	expResult      ChildMatchResult
+	expLegacyMatch bool
+}
+
+func TestMatchChildValues(t *testing.T) {
+
+	// All tests check queue entries against id=1, traffic-class=2.
+	tests := []childMatchTestSpec{
+		{
+			name: "All children present and matching",
+			config: []xutils.PathType{
+				{"profile", "queue/id+1", "traffic-class+2"},
+			},
+			expResult:      ChildMatch,
+			expLegacyMatch: true,
+		},
+		{
+			name: "Child missing",
+			config: []xutils.PathType{
+				{"profile", "queue/id+1", "weight+5"},
+			},
+			expResult:      ChildMissing,
+			expLegacyMatch: true,
+		},
+		{
+			name: "Child duplicated",
+			config: []xutils.PathType{
+				{"profile", "queue/id+1", "traffic-class@2"},
+				{"profile", "queue/id+1", "traffic-class@3"},
+			},
+			expResult:      ChildMultiple,
+			expLegacyMatch: true,
+		},
+		{
+			name: "Child mismatched",
+			config: []xutils.PathType{
+				{"profile", "queue/id+1", "traffic-class+3"},
+			},
+			expResult:      ChildMismatch,
+			expLegacyMatch: false,
+		},
+		{
+			name: "Child missing takes precedence over mismatch",
+			config: []xutils.PathType{
+				{"profile", "queue/id+9", "weight+5"},
+			},
+			expResult:      ChildMissing,
+			expLegacyMatch: false,
+		},
+	}
+
+	reqValues := map[xutils.XFilter]string{
+		GetFilter("id"):            "1",
+		GetFilter("traffic-class"): "2",
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testTree := xpathtest.CreateTree(t, test.config)
+			queueNode := testTree.FindFirstNode(
+				xutils.NewPathType("/profile/queue"))
+			nodes := []xutils.XpathNode{queueNode}
+
+			actResult := MatchChildValues(queueNode, reqValues)
+			if actResult != test.expResult {
+				t.Fatalf("Unexpected result for %s: exp %d, got %d\n",
+					test.name, test.expResult, actResult)
+			}
+
+			// Legacy function only fails on a mismatch it sees before
+			// any missing child, so don't check it where that depends
+			// on map iteration order.
+			if test.expResult != ChildMissing || test.expLegacyMatch {
+				legacyCount := GetCountOfChildNodesWithRequiredValues(
+					nodes, reqValues)
+				if (legacyCount == 1) != test.expLegacyMatch {
+					t.Fatalf("Unexpected legacy result for %s: "+
+						"exp %t, got %d\n",
+						test.name, test.expLegacyMatch, legacyCount)
+				}
+			}
+		})
+	}
+}
+
+func TestGetChildNodeMatchCounts(t *testing.T) {
+
+	testTree := xpathtest.CreateTree(t, []xutils.PathType{
+		{"profile", "queue/id+1", "traffic-class+2"},
+		{"profile", "queue/id+2", "traffic-class+2"},
+		{"profile", "queue/id+3", "traffic-class+2"},
+		{"profile", "queue/id+4", "weight+5"},
+		{"profile", "queue/id+5", "traffic-class@2"},
+		{"profile", "queue/id+5", "traffic-class@3"},
+		{"profile", "queue/id+6", "traffic-class+1"},
+	})
+	queueNodes := GetDescendantNodesFromSingleNode(testTree,
+		[]xutils.XFilter{GetFilter("profile"), GetFilter("queue")})
+
+	expCounts := ChildMatchCounts{
+		Match:    3,
+		Missing:  1,
+		Multiple: 1,
+		Mismatch: 1,
+	}
+	actCounts := GetChildNodeMatchCounts(queueNodes,
+		map[xutils.XFilter]string{GetFilter("traffic-class"): "2"})
+	if actCounts != expCounts {
+		t.Fatalf("Unexpected counts: exp %+v, got %+v\n",
+			expCounts, actCounts)
+	}
+}
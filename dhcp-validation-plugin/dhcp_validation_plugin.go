@@ -0,0 +1,148 @@
+// Copyright (c) 2021, AT&T Intellectual Property Inc.
+// All rights reserved.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"github.com/danos/xpath-plugins/common"
+	"github.com/danos/yang/xpath"
+	"github.com/danos/yang/xpath/xutils"
+)
+
+var RegistrationData = []xpath.CustomFunctionInfo{
+	{
+		Name:          "verify-dhcp-ranges",
+		FnPtr:         verifyDhcpRanges,
+		Args:          []xpath.DatumTypeChecker{xpath.TypeIsNodeset},
+		RetType:       xpath.TypeIsBool,
+		DefaultRetVal: xpath.NewBoolDatum(false),
+	},
+	{
+		Name:          "verify-dhcp-static-mapping",
+		FnPtr:         verifyDhcpStaticMapping,
+		Args:          []xpath.DatumTypeChecker{xpath.TypeIsNodeset},
+		RetType:       xpath.TypeIsBool,
+		DefaultRetVal: xpath.NewBoolDatum(false),
+	},
+}
+
+// Filters used to find required nodes. Values never change, so create once
+// and reuse.
+var dhcpServerFilter = common.GetFilter("dhcp-server")
+var ipAddressFilter = common.GetFilter("ip-address")
+var sharedNetworkFilter = common.GetFilter("shared-network-name")
+var startFilter = common.GetFilter("start")
+var stopFilter = common.GetFilter("stop")
+var subnetFilter = common.GetFilter("subnet")
+
+// verifyDhcpRanges - implementation of verify-dhcp-ranges(<nodeset>)
+//
+// Applied once to the dhcp-server container.  Replaces must statements
+// that, for each range, iterate over the ranges in every subnet of every
+// shared network.  Instead every start-stop range is indexed in a single
+// walk, and checked that:
+//
+//   - the stop address is set, and is not below the start address
+//   - the range is inside its subnet
+//   - the range does not overlap any other range, in any subnet
+//
+//   configd:must "verify-dhcp-ranges(.)"
+//
+func verifyDhcpRanges(
+	args []xpath.Datum,
+) (retBool xpath.Datum) {
+
+	// Function has flexibility to be applied to '.' or any other node,
+	// rather than just the current node, but should only be applied to
+	// a single node.  So, if nodeset is empty or has multiple entries,
+	// return false.
+	ns0 := args[0].Nodeset("verify-dhcp-ranges()")
+	if len(ns0) != 1 {
+		return xpath.NewBoolDatum(false)
+	}
+	serverNode := ns0[0]
+
+	startNodes := common.GetDescendantNodesFromSingleNode(
+		serverNode, []xutils.XFilter{
+			sharedNetworkFilter,
+			subnetFilter,
+			startFilter,
+		})
+
+	ranges := make([]common.AddressRange, 0, len(startNodes))
+	for _, startNode := range startNodes {
+		stop, ok := common.GetSingleChildValue(startNode, stopFilter)
+		if !ok {
+			return xpath.NewBoolDatum(false)
+		}
+		addrs, ok := common.ParseAddressRange(
+			startNode.XValue() + "-" + stop)
+		if !ok {
+			return xpath.NewBoolDatum(false)
+		}
+		subnet, ok := common.ParseAddressPrefixOrRange(
+			startNode.XParent().XValue())
+		if !ok || !subnet.Contains(addrs) {
+			return xpath.NewBoolDatum(false)
+		}
+		ranges = append(ranges, addrs)
+	}
+
+	return xpath.NewBoolDatum(!common.AnyRangesOverlap(ranges))
+}
+
+// verifyDhcpStaticMapping - implementation of
+// verify-dhcp-static-mapping(<nodeset>)
+//
+// Applied to a subnet static-mapping.  The mapping's ip-address, if set,
+// must be inside the subnet, and must not be inside any of the subnet's
+// start-stop ranges, as it could then also be allocated dynamically.
+//
+//   configd:must "verify-dhcp-static-mapping(.)"
+//
+func verifyDhcpStaticMapping(
+	args []xpath.Datum,
+) (retBool xpath.Datum) {
+
+	// Function has flexibility to be applied to '.' or any other node,
+	// rather than just the current node, but should only be applied to
+	// a single node.  So, if nodeset is empty or has multiple entries,
+	// return false.
+	ns0 := args[0].Nodeset("verify-dhcp-static-mapping()")
+	if len(ns0) != 1 {
+		return xpath.NewBoolDatum(false)
+	}
+	mappingNode := ns0[0]
+	subnetNode := mappingNode.XParent()
+
+	ipAddr, ok := common.GetSingleChildValue(mappingNode, ipAddressFilter)
+	if !ok {
+		return xpath.NewBoolDatum(true)
+	}
+	addr, ok := common.ParseAddressRange(ipAddr)
+	if !ok || addr.Size() != 1 {
+		return xpath.NewBoolDatum(false)
+	}
+
+	subnet, ok := common.ParseAddressPrefixOrRange(subnetNode.XValue())
+	if !ok || !subnet.Contains(addr) {
+		return xpath.NewBoolDatum(false)
+	}
+
+	for _, startNode := range subnetNode.XChildren(
+		startFilter, xutils.Unsorted) {
+		stop, ok := common.GetSingleChildValue(startNode, stopFilter)
+		if !ok {
+			continue
+		}
+		dynamic, ok := common.ParseAddressRange(
+			startNode.XValue() + "-" + stop)
+		if ok && dynamic.Contains(addr) {
+			return xpath.NewBoolDatum(false)
+		}
+	}
+
+	return xpath.NewBoolDatum(true)
+}
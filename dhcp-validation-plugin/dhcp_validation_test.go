@@ -0,0 +1,282 @@
+// Copyright (c) 2021, AT&T Intellectual Property. All rights reserved.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"testing"
+
+	"github.com/danos/yang/xpath"
+	"github.com/danos/yang/xpath/xpathtest"
+	"github.com/danos/yang/xpath/xutils"
+)
+
+type dhcpTestSpec struct {
+	name      string
+	config    []xutils.PathType
+	startPath string
+	expResult bool
+}
+
+func dhcpRangeEntry(network, subnet, start, stop string) xutils.PathType {
+	return xutils.PathType{"service", "dhcp-server",
+		"shared-network-name/tagnode+" + network, "subnet/tagnode+" + subnet,
+		"start/tagnode+" + start, "stop+" + stop}
+}
+
+func dhcpStaticMappingEntry(
+	network, subnet, mapping, ipAddr string,
+) xutils.PathType {
+	return xutils.PathType{"service", "dhcp-server",
+		"shared-network-name/tagnode+" + network, "subnet/tagnode+" + subnet,
+		"static-mapping/tagnode+" + mapping, "ip-address+" + ipAddr}
+}
+
+func TestDhcpRanges(t *testing.T) {
+
+	startPath := "/service/dhcp-server"
+
+	tests := []dhcpTestSpec{
+		{
+			name: "Range inside subnet - PASS",
+			config: []xutils.PathType{
+				dhcpRangeEntry("net1", "10.0.0.0/24", "10.0.0.10",
+					"10.0.0.50"),
+			},
+			startPath: startPath,
+			expResult: true,
+		},
+		{
+			name: "Range covering subnet - PASS",
+			config: []xutils.PathType{
+				dhcpRangeEntry("net1", "10.0.0.0/24", "10.0.0.0",
+					"10.0.0.255"),
+			},
+			startPath: startPath,
+			expResult: true,
+		},
+		{
+			name: "Range ends outside subnet - FAIL",
+			config: []xutils.PathType{
+				dhcpRangeEntry("net1", "10.0.0.0/24", "10.0.0.10",
+					"10.0.1.50"),
+			},
+			startPath: startPath,
+			expResult: false,
+		},
+		{
+			name: "Range starts outside subnet - FAIL",
+			config: []xutils.PathType{
+				dhcpRangeEntry("net1", "10.0.0.0/24", "10.0.1.10",
+					"10.0.1.50"),
+			},
+			startPath: startPath,
+			expResult: false,
+		},
+		{
+			name: "Stop below start - FAIL",
+			config: []xutils.PathType{
+				dhcpRangeEntry("net1", "10.0.0.0/24", "10.0.0.50",
+					"10.0.0.10"),
+			},
+			startPath: startPath,
+			expResult: false,
+		},
+		{
+			name: "No stop - FAIL",
+			config: []xutils.PathType{
+				{"service", "dhcp-server", "shared-network-name/tagnode+net1",
+					"subnet/tagnode+10.0.0.0/24", "start/tagnode+10.0.0.10"},
+			},
+			startPath: startPath,
+			expResult: false,
+		},
+		{
+			name: "Disjoint ranges in same subnet - PASS",
+			config: []xutils.PathType{
+				dhcpRangeEntry("net1", "10.0.0.0/24", "10.0.0.10",
+					"10.0.0.50"),
+				dhcpRangeEntry("net1", "10.0.0.0/24", "10.0.0.51",
+					"10.0.0.99"),
+			},
+			startPath: startPath,
+			expResult: true,
+		},
+		{
+			name: "Overlapping ranges in same subnet - FAIL",
+			config: []xutils.PathType{
+				dhcpRangeEntry("net1", "10.0.0.0/24", "10.0.0.10",
+					"10.0.0.50"),
+				dhcpRangeEntry("net1", "10.0.0.0/24", "10.0.0.50",
+					"10.0.0.99"),
+			},
+			startPath: startPath,
+			expResult: false,
+		},
+		{
+			name: "Overlapping ranges in other shared network - FAIL",
+			config: []xutils.PathType{
+				dhcpRangeEntry("net1", "10.0.0.0/24", "10.0.0.10",
+					"10.0.0.50"),
+				dhcpRangeEntry("net2", "10.0.0.0/23", "10.0.0.40",
+					"10.0.1.50"),
+			},
+			startPath: startPath,
+			expResult: false,
+		},
+		{
+			name: "Ranges in different subnets - PASS",
+			config: []xutils.PathType{
+				dhcpRangeEntry("net1", "10.0.0.0/24", "10.0.0.10",
+					"10.0.0.50"),
+				dhcpRangeEntry("net1", "10.0.1.0/24", "10.0.1.10",
+					"10.0.1.50"),
+				dhcpRangeEntry("net2", "10.0.2.0/24", "10.0.2.10",
+					"10.0.2.50"),
+			},
+			startPath: startPath,
+			expResult: true,
+		},
+		{
+			name: "Invalid range in later subnet - FAIL",
+			config: []xutils.PathType{
+				dhcpRangeEntry("net1", "10.0.0.0/24", "10.0.0.10",
+					"10.0.0.50"),
+				dhcpRangeEntry("net2", "10.0.2.0/24", "10.0.2.10",
+					"10.0.3.50"),
+			},
+			startPath: startPath,
+			expResult: false,
+		},
+		{
+			name: "Overlap between first and last of three ranges - FAIL",
+			config: []xutils.PathType{
+				dhcpRangeEntry("net1", "10.0.0.0/16", "10.0.0.10",
+					"10.0.9.50"),
+				dhcpRangeEntry("net2", "10.1.0.0/24", "10.1.0.10",
+					"10.1.0.50"),
+				dhcpRangeEntry("net3", "10.0.5.0/24", "10.0.5.10",
+					"10.0.5.50"),
+			},
+			startPath: startPath,
+			expResult: false,
+		},
+		{
+			name: "No ranges - PASS",
+			config: []xutils.PathType{
+				dhcpStaticMappingEntry("net1", "10.0.0.0/24", "host1",
+					"10.0.0.5"),
+			},
+			startPath: startPath,
+			expResult: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testTree := xpathtest.CreateTree(t, test.config)
+
+			testNode := testTree.FindFirstNode(
+				xutils.NewPathType(test.startPath))
+			ns := xpath.NewNodesetDatum([]xutils.XpathNode{testNode})
+
+			actResult :=
+				verifyDhcpRanges([]xpath.Datum{ns}).Boolean(
+					"(unused value)")
+			if test.expResult != actResult {
+				t.Fatalf("Unexpected result for %s: exp %t, got %t\n",
+					test.name, test.expResult, actResult)
+			}
+		})
+	}
+}
+
+func TestDhcpStaticMapping(t *testing.T) {
+
+	startPath :=
+		"/service/dhcp-server/shared-network-name/subnet/static-mapping"
+
+	tests := []dhcpTestSpec{
+		{
+			name: "Mapping inside subnet, no ranges - PASS",
+			config: []xutils.PathType{
+				dhcpStaticMappingEntry("net1", "10.0.0.0/24", "host1",
+					"10.0.0.5"),
+			},
+			startPath: startPath,
+			expResult: true,
+		},
+		{
+			name: "Mapping outside dynamic range - PASS",
+			config: []xutils.PathType{
+				dhcpStaticMappingEntry("net1", "10.0.0.0/24", "host1",
+					"10.0.0.5"),
+				dhcpRangeEntry("net1", "10.0.0.0/24", "10.0.0.10",
+					"10.0.0.50"),
+			},
+			startPath: startPath,
+			expResult: true,
+		},
+		{
+			name: "Mapping inside dynamic range - FAIL",
+			config: []xutils.PathType{
+				dhcpStaticMappingEntry("net1", "10.0.0.0/24", "host1",
+					"10.0.0.50"),
+				dhcpRangeEntry("net1", "10.0.0.0/24", "10.0.0.10",
+					"10.0.0.50"),
+			},
+			startPath: startPath,
+			expResult: false,
+		},
+		{
+			name: "Mapping outside subnet - FAIL",
+			config: []xutils.PathType{
+				dhcpStaticMappingEntry("net1", "10.0.0.0/24", "host1",
+					"10.0.1.5"),
+			},
+			startPath: startPath,
+			expResult: false,
+		},
+		{
+			name: "Mapping in other subnet's dynamic range - PASS",
+			config: []xutils.PathType{
+				dhcpStaticMappingEntry("net1", "10.0.0.0/24", "host1",
+					"10.0.0.5"),
+				dhcpRangeEntry("net2", "10.0.0.0/16", "10.0.0.1",
+					"10.0.0.10"),
+			},
+			startPath: startPath,
+			expResult: true,
+		},
+		{
+			name: "No ip-address - PASS",
+			config: []xutils.PathType{
+				{"service", "dhcp-server", "shared-network-name/tagnode+net1",
+					"subnet/tagnode+10.0.0.0/24",
+					"static-mapping/tagnode+host1",
+					"mac-address+00:11:22:33:44:55"},
+			},
+			startPath: startPath,
+			expResult: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testTree := xpathtest.CreateTree(t, test.config)
+
+			testNode := testTree.FindFirstNode(
+				xutils.NewPathType(test.startPath))
+			ns := xpath.NewNodesetDatum([]xutils.XpathNode{testNode})
+
+			actResult :=
+				verifyDhcpStaticMapping([]xpath.Datum{ns}).Boolean(
+					"(unused value)")
+			if test.expResult != actResult {
+				t.Fatalf("Unexpected result for %s: exp %t, got %t\n",
+					test.name, test.expResult, actResult)
+			}
+		})
+	}
+}
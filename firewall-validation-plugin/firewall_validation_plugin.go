@@ -0,0 +1,421 @@
+// Copyright (c) 2021, AT&T Intellectual Property Inc.
+// All rights reserved.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/danos/xpath-plugins/common"
+	"github.com/danos/yang/xpath"
+	"github.com/danos/yang/xpath/xutils"
+)
+
+var RegistrationData = []xpath.CustomFunctionInfo{
+	{
+		Name:          "is-firewall-ruleset-leafref",
+		FnPtr:         isFirewallRulesetLeafref,
+		Args:          []xpath.DatumTypeChecker{xpath.TypeIsNodeset},
+		RetType:       xpath.TypeIsBool,
+		DefaultRetVal: xpath.NewBoolDatum(false),
+	},
+	{
+		Name:          "is-interface-firewall-ruleset-leafref",
+		FnPtr:         isInterfaceFirewallRulesetLeafref,
+		Args:          []xpath.DatumTypeChecker{xpath.TypeIsNodeset},
+		RetType:       xpath.TypeIsBool,
+		DefaultRetVal: xpath.NewBoolDatum(false),
+	},
+	{
+		Name:          "verify-firewall-rule",
+		FnPtr:         verifyFirewallRule,
+		Args:          []xpath.DatumTypeChecker{xpath.TypeIsNodeset},
+		RetType:       xpath.TypeIsBool,
+		DefaultRetVal: xpath.NewBoolDatum(false),
+	},
+	{
+		Name:          "verify-resource-group",
+		FnPtr:         verifyResourceGroup,
+		Args:          []xpath.DatumTypeChecker{xpath.TypeIsNodeset},
+		RetType:       xpath.TypeIsBool,
+		DefaultRetVal: xpath.NewBoolDatum(false),
+	},
+	{
+		Name:          "verify-resource-group-reason",
+		FnPtr:         verifyResourceGroupReason,
+		Args:          []xpath.DatumTypeChecker{xpath.TypeIsNodeset},
+		RetType:       xpath.TypeIsLiteral,
+		DefaultRetVal: xpath.NewLiteralDatum(""),
+	},
+}
+
+// Filters used to find required nodes. Values never change, so create once
+// and reuse.
+var actionFilter = common.GetFilter("action")
+var addressFilter = common.GetFilter("address")
+var addressGroupFilter = common.GetFilter("address-group")
+var destinationFilter = common.GetFilter("destination")
+var firewallFilter = common.GetFilter("firewall")
+var groupFilter = common.GetFilter("group")
+var nameFilter = common.GetFilter("name")
+var portFilter = common.GetFilter("port")
+var portGroupFilter = common.GetFilter("port-group")
+var protocolFilter = common.GetFilter("protocol")
+var resourcesFilter = common.GetFilter("resources")
+var securityFilter = common.GetFilter("security")
+var sourceFilter = common.GetFilter("source")
+
+// Firewall rules are numbered from 1 to maxRuleNumber.
+const maxRuleNumber = 9999
+
+// isFirewallRulesetLeafref - implementation of
+// is-firewall-ruleset-leafref(<nodeset>)
+//
+// Used on interface firewall in / out / local references.  Full must
+// statement:
+//
+//   must "/security:security/firewall:firewall/firewall:name" +
+//        "[firewall:ruleset-name = current()]";
+//
+// Implemented with plugin as:
+//
+//   configd:must "is-firewall-ruleset-leafref(.)"
+//
+func isFirewallRulesetLeafref(
+	args []xpath.Datum,
+) (retBool xpath.Datum) {
+
+	// Function has flexibility to be applied to '.' or any other node,
+	// rather than just the current node, but should only be applied to
+	// a single node.  So, if nodeset is empty or has multiple entries,
+	// return false.
+	ns0 := args[0].Nodeset("is-firewall-ruleset-leafref()")
+	if len(ns0) != 1 {
+		return xpath.NewBoolDatum(false)
+	}
+	refNode := ns0[0]
+
+	_, ok := getFirewallRulesetNode(refNode.XRoot(), refNode.XValue())
+	return xpath.NewBoolDatum(ok)
+}
+
+// isInterfaceFirewallRulesetLeafref - implementation of
+// is-interface-firewall-ruleset-leafref(<nodeset>)
+//
+// As is-firewall-ruleset-leafref(), but the interface or VIF the reference
+// is attached to (the parent of its firewall container) must also resolve
+// by name, as for is-interface-leafref().
+//
+//   configd:must "is-interface-firewall-ruleset-leafref(.)"
+//
+func isInterfaceFirewallRulesetLeafref(
+	args []xpath.Datum,
+) (retBool xpath.Datum) {
+
+	// Function has flexibility to be applied to '.' or any other node,
+	// rather than just the current node, but should only be applied to
+	// a single node.  So, if nodeset is empty or has multiple entries,
+	// return false.
+	ns0 := args[0].Nodeset("is-interface-firewall-ruleset-leafref()")
+	if len(ns0) != 1 {
+		return xpath.NewBoolDatum(false)
+	}
+	refNode := ns0[0]
+	root := refNode.XRoot()
+
+	intfNode := refNode.XParent().XParent()
+	if intfNode == nil {
+		return xpath.NewBoolDatum(false)
+	}
+	if _, ok := common.GetInterfaceNode(
+		root, common.GetInterfaceName(intfNode), nil); !ok {
+		return xpath.NewBoolDatum(false)
+	}
+
+	_, ok := getFirewallRulesetNode(root, refNode.XValue())
+	return xpath.NewBoolDatum(ok)
+}
+
+// getFirewallRulesetNode - find the firewall ruleset with the given name.
+func getFirewallRulesetNode(
+	root xutils.XpathNode,
+	rulesetName string,
+) (xutils.XpathNode, bool) {
+	rulesetNodes := common.GetDescendantNodesFromSingleNode(
+		root, []xutils.XFilter{
+			securityFilter,
+			firewallFilter,
+			nameFilter,
+		})
+	for _, rulesetNode := range rulesetNodes {
+		if rulesetNode.XValue() == rulesetName {
+			return rulesetNode, true
+		}
+	}
+	return nil, false
+}
+
+// verifyFirewallRule - implementation of verify-firewall-rule(<nodeset>)
+//
+// Applied to a firewall rule, and replaces these must statements:
+//
+//   must "action";
+//   must "not(source/port or destination/port) or protocol";
+//
+// It additionally checks that the rule number is from 1 to maxRuleNumber,
+// and that each source or destination address is either an address-group
+// or a valid address, and each port either a port-group or a valid port.
+//
+//   configd:must "verify-firewall-rule(.)"
+//
+func verifyFirewallRule(
+	args []xpath.Datum,
+) (retBool xpath.Datum) {
+
+	// Function has flexibility to be applied to '.' or any other node,
+	// rather than just the current node, but should only be applied to
+	// a single node.  So, if nodeset is empty or has multiple entries,
+	// return false.
+	ns0 := args[0].Nodeset("verify-firewall-rule()")
+	if len(ns0) != 1 {
+		return xpath.NewBoolDatum(false)
+	}
+	ruleNode := ns0[0]
+
+	if !isValidRuleNumber(ruleNode.XValue()) {
+		return xpath.NewBoolDatum(false)
+	}
+
+	// 'action'
+	if _, ok := common.GetSingleChildValue(ruleNode, actionFilter); !ok {
+		return xpath.NewBoolDatum(false)
+	}
+
+	// 'not(source/port or destination/port) or protocol'
+	endpointNodes := getRuleEndpointNodes(ruleNode)
+	portNodes := common.GetDescendantNodes(
+		endpointNodes, []xutils.XFilter{portFilter})
+	if len(portNodes) > 0 {
+		if _, ok := common.GetSingleChildValue(
+			ruleNode, protocolFilter); !ok {
+			return xpath.NewBoolDatum(false)
+		}
+	}
+
+	// Addresses may only name address-groups, and ports port-groups.
+	groupNodes := common.GetDescendantNodesFromSingleNode(
+		ruleNode.XRoot(), []xutils.XFilter{resourcesFilter, groupFilter})
+	if len(groupNodes) > 1 {
+		return xpath.NewBoolDatum(false)
+	}
+	addressNodes := common.GetDescendantNodes(
+		endpointNodes, []xutils.XFilter{addressFilter})
+	for _, addressNode := range addressNodes {
+		if !isValidRuleReference(groupNodes,
+			resourceGroupTypes["address-group"], addressNode.XValue()) {
+			return xpath.NewBoolDatum(false)
+		}
+	}
+	for _, portNode := range portNodes {
+		if !isValidRuleReference(groupNodes,
+			resourceGroupTypes["port-group"], portNode.XValue()) {
+			return xpath.NewBoolDatum(false)
+		}
+	}
+
+	return xpath.NewBoolDatum(true)
+}
+
+// isValidRuleNumber - true if ruleNumber is from 1 to maxRuleNumber.
+func isValidRuleNumber(ruleNumber string) bool {
+	num, err := strconv.ParseUint(ruleNumber, 10, 64)
+	if err != nil {
+		return false
+	}
+	return num > 0 && num <= maxRuleNumber
+}
+
+// getRuleEndpointNodes - return the rule's source and destination nodes.
+func getRuleEndpointNodes(ruleNode xutils.XpathNode) []xutils.XpathNode {
+	var endpoints []xutils.XpathNode
+	endpoints = append(endpoints,
+		ruleNode.XChildren(sourceFilter, xutils.Unsorted)...)
+	endpoints = append(endpoints,
+		ruleNode.XChildren(destinationFilter, xutils.Unsorted)...)
+	return endpoints
+}
+
+// isValidRuleReference - true if value names a group of the given type
+// under any of groupNodes, or is itself a valid member of that type.
+func isValidRuleReference(
+	groupNodes []xutils.XpathNode,
+	groupType resourceGroupType,
+	value string,
+) bool {
+	for _, groupNode := range groupNodes {
+		for _, node := range groupNode.XChildren(
+			groupType.groupFilter, xutils.Unsorted) {
+			if node.XValue() == value {
+				return true
+			}
+		}
+	}
+	return groupType.isValid(value)
+}
+
+// resourceGroupType - how to validate the members of one type of resource
+// group.  Groups may also include other groups of the same type by name.
+type resourceGroupType struct {
+	groupFilter  xutils.XFilter
+	memberFilter xutils.XFilter
+	memberName   string
+	isValid      func(string) bool
+}
+
+var resourceGroupTypes = map[string]resourceGroupType{
+	"address-group": {
+		groupFilter:  addressGroupFilter,
+		memberFilter: addressFilter,
+		memberName:   "address",
+		isValid:      common.IsValidAddressOrPrefix,
+	},
+	"port-group": {
+		groupFilter:  portGroupFilter,
+		memberFilter: portFilter,
+		memberName:   "port",
+		isValid:      common.IsValidPortOrName,
+	},
+}
+
+// verifyResourceGroup - implementation of verify-resource-group(<nodeset>)
+//
+// Applied to an address-group or port-group, and checks that:
+//
+//   - every address is a valid address, prefix or address range
+//   - every port is a valid port number, range or service name
+//   - every nested group reference is to a group of the same type that
+//     exists, and following nested references never leads to a cycle
+//
+//   configd:must "verify-resource-group(.)"
+//
+// The reason for any failure is available from
+// verify-resource-group-reason(.), for use in the error message.
+func verifyResourceGroup(
+	args []xpath.Datum,
+) (retBool xpath.Datum) {
+
+	// Function has flexibility to be applied to '.' or any other node,
+	// rather than just the current node, but should only be applied to
+	// a single node.  So, if nodeset is empty or has multiple entries,
+	// return false.
+	ns0 := args[0].Nodeset("verify-resource-group()")
+	if len(ns0) != 1 {
+		return xpath.NewBoolDatum(false)
+	}
+
+	return xpath.NewBoolDatum(getResourceGroupError(ns0[0]) == "")
+}
+
+// verifyResourceGroupReason - implementation of
+// verify-resource-group-reason(<nodeset>)
+//
+// Returns the reason verify-resource-group(.) fails for the same group, or
+// an empty string if it passes.
+func verifyResourceGroupReason(
+	args []xpath.Datum,
+) (retString xpath.Datum) {
+
+	ns0 := args[0].Nodeset("verify-resource-group-reason()")
+	if len(ns0) != 1 {
+		return xpath.NewLiteralDatum("")
+	}
+
+	return xpath.NewLiteralDatum(getResourceGroupError(ns0[0]))
+}
+
+// getResourceGroupError - return the reason the group is invalid, or an
+// empty string if it is valid.
+func getResourceGroupError(groupNode xutils.XpathNode) string {
+	groupTypeName := groupNode.XName()
+	groupType, ok := resourceGroupTypes[groupTypeName]
+	if !ok {
+		return "'" + groupTypeName + "' is not a resource group"
+	}
+	groupName := groupNode.XValue()
+
+	for _, memberNode := range groupNode.XChildren(
+		groupType.memberFilter, xutils.Unsorted) {
+		if !groupType.isValid(memberNode.XValue()) {
+			return groupTypeName + " '" + groupName + "' has invalid " +
+				groupType.memberName + " '" + memberNode.XValue() + "'"
+		}
+	}
+
+	// Index all groups of this type so nested references can be followed.
+	groups := make(map[string]xutils.XpathNode)
+	for _, node := range groupNode.XParent().XChildren(
+		groupType.groupFilter, xutils.Unsorted) {
+		groups[node.XValue()] = node
+	}
+
+	// Unknown references from nested groups are left to the checks on
+	// those groups.
+	for _, refNode := range groupNode.XChildren(
+		groupType.groupFilter, xutils.Unsorted) {
+		if _, ok := groups[refNode.XValue()]; !ok {
+			return groupTypeName + " '" + groupName +
+				"' references unknown " + groupTypeName + " '" +
+				refNode.XValue() + "'"
+		}
+	}
+
+	checker := groupRefChecker{
+		groupFilter: groupType.groupFilter,
+		groups:      groups,
+		done:        make(map[string]bool),
+	}
+	if reason := checker.check(groupName); reason != "" {
+		return groupTypeName + " '" + groupName + "' " + reason
+	}
+	return ""
+}
+
+// groupRefChecker - depth first walk of nested group references, tracking
+// the current path of references to detect cycles.
+type groupRefChecker struct {
+	groupFilter xutils.XFilter
+	groups      map[string]xutils.XpathNode
+	path        []string
+	done        map[string]bool
+}
+
+func (c *groupRefChecker) check(groupName string) string {
+	for i, name := range c.path {
+		if name == groupName {
+			cycle := append(c.path[i:len(c.path):len(c.path)], groupName)
+			return "has reference cycle: " + strings.Join(cycle, " -> ")
+		}
+	}
+	if c.done[groupName] {
+		return ""
+	}
+
+	c.path = append(c.path, groupName)
+	for _, refNode := range c.groups[groupName].XChildren(
+		c.groupFilter, xutils.Unsorted) {
+		refName := refNode.XValue()
+		if _, ok := c.groups[refName]; !ok {
+			continue
+		}
+		if reason := c.check(refName); reason != "" {
+			return reason
+		}
+	}
+	c.path = c.path[:len(c.path)-1]
+	c.done[groupName] = true
+
+	return ""
+}
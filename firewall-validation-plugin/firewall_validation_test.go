@@ -0,0 +1,493 @@
+// Copyright (c) 2021, AT&T Intellectual Property. All rights reserved.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"testing"
+
+	"github.com/danos/yang/xpath"
+	"github.com/danos/yang/xpath/xpathtest"
+	"github.com/danos/yang/xpath/xutils"
+)
+
+type firewallTestSpec struct {
+	name      string
+	config    []xutils.PathType
+	startPath string
+	expResult bool
+}
+
+func TestFirewallRulesetLeafref(t *testing.T) {
+
+	rulesets := []xutils.PathType{
+		{"security", "firewall", "name/ruleset-name+fw-in", "rule/tagnode+10",
+			"action+accept"},
+		{"security", "firewall", "name/ruleset-name+fw-out",
+			"rule/tagnode+10", "action+drop"},
+	}
+
+	tests := []firewallTestSpec{
+		{
+			name: "Interface in ruleset exists - PASS",
+			config: append([]xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "firewall",
+					"in@fw-in"},
+			}, rulesets...),
+			startPath: "/interfaces/dataplane/firewall/in",
+			expResult: true,
+		},
+		{
+			name: "VIF out ruleset exists - PASS",
+			config: append([]xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "vif/tagnode+10",
+					"firewall", "out@fw-out"},
+			}, rulesets...),
+			startPath: "/interfaces/dataplane/vif/firewall/out",
+			expResult: true,
+		},
+		{
+			name: "Ruleset exists, not attached to interface - PASS",
+			config: append([]xutils.PathType{
+				{"service", "other/tagnode+dp0s1", "firewall", "in@fw-in"},
+			}, rulesets...),
+			startPath: "/service/other/firewall/in",
+			expResult: true,
+		},
+		{
+			name: "Interface local ruleset missing - FAIL",
+			config: append([]xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "firewall",
+					"local@fw-local"},
+			}, rulesets...),
+			startPath: "/interfaces/dataplane/firewall/local",
+			expResult: false,
+		},
+		{
+			name: "No rulesets - FAIL",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "firewall",
+					"in@fw-in"},
+			},
+			startPath: "/interfaces/dataplane/firewall/in",
+			expResult: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testTree := xpathtest.CreateTree(t, test.config)
+
+			testNode := testTree.FindFirstNode(
+				xutils.NewPathType(test.startPath))
+			ns := xpath.NewNodesetDatum([]xutils.XpathNode{testNode})
+
+			actResult :=
+				isFirewallRulesetLeafref([]xpath.Datum{ns}).Boolean(
+					"(unused value)")
+			if test.expResult != actResult {
+				t.Fatalf("Unexpected result for %s: exp %t, got %t\n",
+					test.name, test.expResult, actResult)
+			}
+		})
+	}
+}
+
+func TestInterfaceFirewallRulesetLeafref(t *testing.T) {
+
+	rulesets := []xutils.PathType{
+		{"security", "firewall", "name/ruleset-name+fw-in", "rule/tagnode+10",
+			"action+accept"},
+	}
+
+	tests := []firewallTestSpec{
+		{
+			name: "Interface in ruleset exists - PASS",
+			config: append([]xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "firewall",
+					"in@fw-in"},
+			}, rulesets...),
+			startPath: "/interfaces/dataplane/firewall/in",
+			expResult: true,
+		},
+		{
+			name: "Bonding VIF local ruleset exists - PASS",
+			config: append([]xutils.PathType{
+				{"interfaces", "bonding/tagnode+dp0bond0", "vif/tagnode+10",
+					"firewall", "local@fw-in"},
+			}, rulesets...),
+			startPath: "/interfaces/bonding/vif/firewall/local",
+			expResult: true,
+		},
+		{
+			name: "Ruleset exists, not attached to interface - FAIL",
+			config: append([]xutils.PathType{
+				{"service", "other/tagnode+dp0s1", "firewall", "in@fw-in"},
+			}, rulesets...),
+			startPath: "/service/other/firewall/in",
+			expResult: false,
+		},
+		{
+			name: "Interface ruleset missing - FAIL",
+			config: append([]xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "firewall",
+					"out@fw-out"},
+			}, rulesets...),
+			startPath: "/interfaces/dataplane/firewall/out",
+			expResult: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testTree := xpathtest.CreateTree(t, test.config)
+
+			testNode := testTree.FindFirstNode(
+				xutils.NewPathType(test.startPath))
+			ns := xpath.NewNodesetDatum([]xutils.XpathNode{testNode})
+
+			actResult :=
+				isInterfaceFirewallRulesetLeafref([]xpath.Datum{ns}).Boolean(
+					"(unused value)")
+			if test.expResult != actResult {
+				t.Fatalf("Unexpected result for %s: exp %t, got %t\n",
+					test.name, test.expResult, actResult)
+			}
+		})
+	}
+}
+
+func TestFirewallRule(t *testing.T) {
+
+	groups := []xutils.PathType{
+		{"resources", "group", "address-group/tagnode+servers",
+			"address@10.0.0.1"},
+		{"resources", "group", "port-group/tagnode+web", "port@80"},
+		{"resources", "group", "address-group/tagnode+shared",
+			"address@10.0.0.2"},
+		{"resources", "group", "port-group/tagnode+shared", "port@8080"},
+	}
+
+	tests := []firewallTestSpec{
+		{
+			name: "Action only - PASS",
+			config: []xutils.PathType{
+				{"security", "firewall", "name/ruleset-name+fw1",
+					"rule/tagnode+10", "action+accept"},
+			},
+			startPath: "/security/firewall/name/rule",
+			expResult: true,
+		},
+		{
+			name: "No action - FAIL",
+			config: []xutils.PathType{
+				{"security", "firewall", "name/ruleset-name+fw1",
+					"rule/tagnode+10", "protocol+tcp"},
+			},
+			startPath: "/security/firewall/name/rule",
+			expResult: false,
+		},
+		{
+			name: "Destination port with protocol - PASS",
+			config: []xutils.PathType{
+				{"security", "firewall", "name/ruleset-name+fw1",
+					"rule/tagnode+10", "action+accept"},
+				{"security", "firewall", "name/ruleset-name+fw1",
+					"rule/tagnode+10", "protocol+tcp"},
+				{"security", "firewall", "name/ruleset-name+fw1",
+					"rule/tagnode+10", "destination", "port+22"},
+			},
+			startPath: "/security/firewall/name/rule",
+			expResult: true,
+		},
+		{
+			name: "Source port without protocol - FAIL",
+			config: []xutils.PathType{
+				{"security", "firewall", "name/ruleset-name+fw1",
+					"rule/tagnode+10", "action+accept"},
+				{"security", "firewall", "name/ruleset-name+fw1",
+					"rule/tagnode+10", "source", "port+22"},
+			},
+			startPath: "/security/firewall/name/rule",
+			expResult: false,
+		},
+		{
+			name: "Address without protocol - PASS",
+			config: []xutils.PathType{
+				{"security", "firewall", "name/ruleset-name+fw1",
+					"rule/tagnode+10", "action+drop"},
+				{"security", "firewall", "name/ruleset-name+fw1",
+					"rule/tagnode+10", "source", "address+10.0.0.1"},
+			},
+			startPath: "/security/firewall/name/rule",
+			expResult: true,
+		},
+		{
+			name: "Address and port groups - PASS",
+			config: append([]xutils.PathType{
+				{"security", "firewall", "name/ruleset-name+fw1",
+					"rule/tagnode+10", "action+accept"},
+				{"security", "firewall", "name/ruleset-name+fw1",
+					"rule/tagnode+10", "protocol+tcp"},
+				{"security", "firewall", "name/ruleset-name+fw1",
+					"rule/tagnode+10", "destination", "address+servers"},
+				{"security", "firewall", "name/ruleset-name+fw1",
+					"rule/tagnode+10", "destination", "port+web"},
+			}, groups...),
+			startPath: "/security/firewall/name/rule",
+			expResult: true,
+		},
+		{
+			name: "Address and port groups share a name - PASS",
+			config: append([]xutils.PathType{
+				{"security", "firewall", "name/ruleset-name+fw1",
+					"rule/tagnode+10", "action+accept"},
+				{"security", "firewall", "name/ruleset-name+fw1",
+					"rule/tagnode+10", "protocol+udp"},
+				{"security", "firewall", "name/ruleset-name+fw1",
+					"rule/tagnode+10", "source", "address+shared"},
+				{"security", "firewall", "name/ruleset-name+fw1",
+					"rule/tagnode+10", "destination", "port+shared"},
+			}, groups...),
+			startPath: "/security/firewall/name/rule",
+			expResult: true,
+		},
+		{
+			name: "Address names port group - FAIL",
+			config: append([]xutils.PathType{
+				{"security", "firewall", "name/ruleset-name+fw1",
+					"rule/tagnode+10", "action+accept"},
+				{"security", "firewall", "name/ruleset-name+fw1",
+					"rule/tagnode+10", "source", "address+web"},
+			}, groups...),
+			startPath: "/security/firewall/name/rule",
+			expResult: false,
+		},
+		{
+			name: "Unknown address group - FAIL",
+			config: []xutils.PathType{
+				{"security", "firewall", "name/ruleset-name+fw1",
+					"rule/tagnode+10", "action+accept"},
+				{"security", "firewall", "name/ruleset-name+fw1",
+					"rule/tagnode+10", "destination", "address+servers"},
+			},
+			startPath: "/security/firewall/name/rule",
+			expResult: false,
+		},
+		{
+			name: "Highest rule number - PASS",
+			config: []xutils.PathType{
+				{"security", "firewall", "name/ruleset-name+fw1",
+					"rule/tagnode+9999", "action+accept"},
+			},
+			startPath: "/security/firewall/name/rule",
+			expResult: true,
+		},
+		{
+			name: "Rule number zero - FAIL",
+			config: []xutils.PathType{
+				{"security", "firewall", "name/ruleset-name+fw1",
+					"rule/tagnode+0", "action+accept"},
+			},
+			startPath: "/security/firewall/name/rule",
+			expResult: false,
+		},
+		{
+			name: "Rule number too high - FAIL",
+			config: []xutils.PathType{
+				{"security", "firewall", "name/ruleset-name+fw1",
+					"rule/tagnode+10000", "action+accept"},
+			},
+			startPath: "/security/firewall/name/rule",
+			expResult: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testTree := xpathtest.CreateTree(t, test.config)
+
+			testNode := testTree.FindFirstNode(
+				xutils.NewPathType(test.startPath))
+			ns := xpath.NewNodesetDatum([]xutils.XpathNode{testNode})
+
+			actResult :=
+				verifyFirewallRule([]xpath.Datum{ns}).Boolean(
+					"(unused value)")
+			if test.expResult != actResult {
+				t.Fatalf("Unexpected result for %s: exp %t, got %t\n",
+					test.name, test.expResult, actResult)
+			}
+		})
+	}
+}
+
+type resourceGroupTestSpec struct {
+	name      string
+	config    []xutils.PathType
+	startPath string
+	expReason string
+}
+
+func TestResourceGroup(t *testing.T) {
+
+	tests := []resourceGroupTestSpec{
+		{
+			name: "Valid addresses - PASS",
+			config: []xutils.PathType{
+				{"resources", "group", "address-group/tagnode+A",
+					"address@10.0.0.1"},
+				{"resources", "group", "address-group/tagnode+A",
+					"address@10.0.1.1-10.0.1.9"},
+				{"resources", "group", "address-group/tagnode+A",
+					"address@2001:db8::1"},
+			},
+			startPath: "/resources/group/address-group",
+			expReason: "",
+		},
+		{
+			name: "Invalid address - FAIL",
+			config: []xutils.PathType{
+				{"resources", "group", "address-group/tagnode+A",
+					"address@10.0.0.1"},
+				{"resources", "group", "address-group/tagnode+A",
+					"address@10.0.0.300"},
+			},
+			startPath: "/resources/group/address-group",
+			expReason: "address-group 'A' has invalid address '10.0.0.300'",
+		},
+		{
+			name: "Reversed address range - FAIL",
+			config: []xutils.PathType{
+				{"resources", "group", "address-group/tagnode+A",
+					"address@10.0.0.9-10.0.0.1"},
+			},
+			startPath: "/resources/group/address-group",
+			expReason: "address-group 'A' has invalid address " +
+				"'10.0.0.9-10.0.0.1'",
+		},
+		{
+			name: "Valid ports - PASS",
+			config: []xutils.PathType{
+				{"resources", "group", "port-group/tagnode+P", "port@80"},
+				{"resources", "group", "port-group/tagnode+P",
+					"port@8000-8080"},
+				{"resources", "group", "port-group/tagnode+P", "port@https"},
+			},
+			startPath: "/resources/group/port-group",
+			expReason: "",
+		},
+		{
+			name: "Invalid port - FAIL",
+			config: []xutils.PathType{
+				{"resources", "group", "port-group/tagnode+P", "port@0"},
+			},
+			startPath: "/resources/group/port-group",
+			expReason: "port-group 'P' has invalid port '0'",
+		},
+		{
+			name: "Nested groups - PASS",
+			config: []xutils.PathType{
+				{"resources", "group", "address-group/tagnode+A",
+					"address-group@B"},
+				{"resources", "group", "address-group/tagnode+A",
+					"address-group@C"},
+				{"resources", "group", "address-group/tagnode+B",
+					"address-group@C"},
+				{"resources", "group", "address-group/tagnode+C",
+					"address@10.0.0.1"},
+			},
+			startPath: "/resources/group/address-group",
+			expReason: "",
+		},
+		{
+			name: "Unknown nested group - FAIL",
+			config: []xutils.PathType{
+				{"resources", "group", "port-group/tagnode+P",
+					"port-group@Q"},
+			},
+			startPath: "/resources/group/port-group",
+			expReason: "port-group 'P' references unknown port-group 'Q'",
+		},
+		{
+			name: "Nested group of other type - FAIL",
+			config: []xutils.PathType{
+				{"resources", "group", "address-group/tagnode+A",
+					"address-group@P"},
+				{"resources", "group", "port-group/tagnode+P", "port@80"},
+			},
+			startPath: "/resources/group/address-group",
+			expReason: "address-group 'A' references unknown " +
+				"address-group 'P'",
+		},
+		{
+			name: "Self reference - FAIL",
+			config: []xutils.PathType{
+				{"resources", "group", "address-group/tagnode+A",
+					"address-group@A"},
+			},
+			startPath: "/resources/group/address-group",
+			expReason: "address-group 'A' has reference cycle: A -> A",
+		},
+		{
+			name: "Indirect cycle - FAIL",
+			config: []xutils.PathType{
+				{"resources", "group", "port-group/tagnode+P",
+					"port-group@Q"},
+				{"resources", "group", "port-group/tagnode+Q",
+					"port-group@R"},
+				{"resources", "group", "port-group/tagnode+R",
+					"port-group@P"},
+			},
+			startPath: "/resources/group/port-group",
+			expReason: "port-group 'P' has reference cycle: P -> Q -> R -> P",
+		},
+		{
+			name: "Cycle in referenced group - FAIL",
+			config: []xutils.PathType{
+				{"resources", "group", "port-group/tagnode+P",
+					"port-group@Q"},
+				{"resources", "group", "port-group/tagnode+Q",
+					"port-group@R"},
+				{"resources", "group", "port-group/tagnode+R",
+					"port-group@Q"},
+			},
+			startPath: "/resources/group/port-group",
+			expReason: "port-group 'P' has reference cycle: Q -> R -> Q",
+		},
+		{
+			name: "Not a resource group - FAIL",
+			config: []xutils.PathType{
+				{"resources", "group", "icmp-group/tagnode+I", "name@echo"},
+			},
+			startPath: "/resources/group/icmp-group",
+			expReason: "'icmp-group' is not a resource group",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testTree := xpathtest.CreateTree(t, test.config)
+
+			testNode := testTree.FindFirstNode(
+				xutils.NewPathType(test.startPath))
+			ns := xpath.NewNodesetDatum([]xutils.XpathNode{testNode})
+
+			actReason := verifyResourceGroupReason(
+				[]xpath.Datum{ns}).String("(unused value)")
+			if test.expReason != actReason {
+				t.Fatalf("Unexpected reason for %s:\n exp '%s'\n got '%s'\n",
+					test.name, test.expReason, actReason)
+			}
+
+			expResult := test.expReason == ""
+			actResult := verifyResourceGroup(
+				[]xpath.Datum{ns}).Boolean("(unused value)")
+			if expResult != actResult {
+				t.Fatalf("Unexpected result for %s: exp %t, got %t\n",
+					test.name, expResult, actResult)
+			}
+		})
+	}
+}
@@ -6,8 +6,6 @@
 package main
 
 import (
-	"strings"
-
 	"github.com/danos/xpath-plugins/common"
 	"github.com/danos/yang/xpath"
 	"github.com/danos/yang/xpath/xutils"
@@ -35,14 +33,42 @@ var RegistrationData = []xpath.CustomFunctionInfo{
 		RetType:       xpath.TypeIsBool,
 		DefaultRetVal: xpath.NewBoolDatum(false),
 	},
+	{
+		Name:          "interface-exists-in-state",
+		FnPtr:         interfaceExistsInState,
+		Args:          []xpath.DatumTypeChecker{xpath.TypeIsNodeset},
+		RetType:       xpath.TypeIsBool,
+		DefaultRetVal: xpath.NewBoolDatum(false),
+	},
+	{
+		Name:          "interface-exists-only-in-state",
+		FnPtr:         interfaceExistsOnlyInState,
+		Args:          []xpath.DatumTypeChecker{xpath.TypeIsNodeset},
+		RetType:       xpath.TypeIsBool,
+		DefaultRetVal: xpath.NewBoolDatum(false),
+	},
+	{
+		Name:  "interface-in-routing-instance",
+		FnPtr: interfaceInRoutingInstance,
+		Args: []xpath.DatumTypeChecker{
+			xpath.TypeIsNodeset,
+			xpath.TypeIsLiteral,
+		},
+		RetType:       xpath.TypeIsBool,
+		DefaultRetVal: xpath.NewBoolDatum(false),
+	},
+	{
+		Name:  "same-routing-instance",
+		FnPtr: sameRoutingInstance,
+		Args: []xpath.DatumTypeChecker{
+			xpath.TypeIsNodeset,
+			xpath.TypeIsNodeset,
+		},
+		RetType:       xpath.TypeIsBool,
+		DefaultRetVal: xpath.NewBoolDatum(false),
+	},
 }
 
-// Filters used to find required nodes. Values never change, so create once
-// and reuse.
-var intfFilter = common.GetFilter("interfaces")
-var intfTypesFilter = common.GetFilter("*")
-var vifFilter = common.GetFilter("vif")
-
 // isInterfaceLeafref - implementation of is-interface-leafref(<nodeset>)
 // Matches any interface, including VIFs
 func isInterfaceLeafref(
@@ -90,75 +116,127 @@ func isInterfaceLeafrefInternal(
 		return xpath.NewBoolDatum(false)
 	}
 	srcNode := ns0[0]
-	intfVal, isVIF, vifVal := parseInterfaceName(srcNode.XValue())
-	root := srcNode.XRoot()
 
-	intfNodes := root.XChildren(intfFilter, xutils.Sorted)
-	if intfNodes == nil || len(intfNodes) > 1 {
+	// All VIFs are L3, so interfaceFilter only applies to base interfaces.
+	_, ok := common.GetInterfaceNode(
+		srcNode.XRoot(), srcNode.XValue(), interfaceFilter)
+	return xpath.NewBoolDatum(ok)
+}
+
+// interfaceExistsInState - implementation of
+// interface-exists-in-state(<nodeset>)
+// Matches any interface or VIF, of any type, that is either configured or
+// present in state (operational) data.  Intended for use in state must /
+// when statements where the interface referenced may not be configured,
+// eg:
+//
+//   configd:must "interface-exists-in-state(.)"
+//
+func interfaceExistsInState(
+	args []xpath.Datum,
+) (retBool xpath.Datum) {
+	return interfaceExistsInStateInternal(args,
+		"interface-exists-in-state()", false)
+}
+
+// interfaceExistsOnlyInState - implementation of
+// interface-exists-only-in-state(<nodeset>)
+// Matches any interface or VIF that is present in state data but is not
+// configured, eg a discovered dataplane interface with no configuration.
+func interfaceExistsOnlyInState(
+	args []xpath.Datum,
+) (retBool xpath.Datum) {
+	return interfaceExistsInStateInternal(args,
+		"interface-exists-only-in-state()", true)
+}
+
+func interfaceExistsInStateInternal(
+	args []xpath.Datum,
+	fnName string,
+	onlyInState bool,
+) (retBool xpath.Datum) {
+
+	// Function has flexibility to be applied to '.' or any other node,
+	// rather than just the current node, but should only be applied to
+	// a single node.  So, if nodeset is empty or has multiple entries,
+	// return false.
+	ns0 := args[0].Nodeset(fnName)
+	if len(ns0) != 1 {
 		return xpath.NewBoolDatum(false)
 	}
+	srcNode := ns0[0]
+	root := srcNode.XRoot()
 
-	// Now get all interface list entries (we get one big list with all
-	// interface list entries, XValue() == key).  This means we don't need
-	// to care about tagnode / ifname etc.
-	intfListEntries := intfNodes[0].XChildren(intfTypesFilter, xutils.Sorted)
-	if intfListEntries == nil {
+	if _, ok := common.GetInterfaceNodeInState(
+		root, srcNode.XValue(), []string{}); !ok {
 		return xpath.NewBoolDatum(false)
 	}
+	if !onlyInState {
+		return xpath.NewBoolDatum(true)
+	}
+
+	_, configured := common.GetInterfaceNode(
+		root, srcNode.XValue(), []string{})
+	return xpath.NewBoolDatum(!configured)
+}
+
+// interfaceInRoutingInstance - implementation of
+// interface-in-routing-instance(<nodeset>, <vrf-name>)
+// Matches any interface or VIF, resolved as for is-interface-leafref(),
+// that is in the named routing instance.  Interfaces not bound to any
+// routing instance are in 'default'.  eg:
+//
+//   configd:must "interface-in-routing-instance(., 'blue')"
+//
+func interfaceInRoutingInstance(
+	args []xpath.Datum,
+) (retBool xpath.Datum) {
 
-	for _, intf := range intfListEntries {
-		if intf.XValue() != intfVal {
-			// Base interface name doesn't match. Next!
-			continue
-		}
-		if !isVIF {
-			if ignoreInterface(intf.XName(), interfaceFilter) {
-				// Used to ignore specific interface types (but not VIFs
-				// on these interfaces).  Typical use is to remove L2
-				// interfaces (eg switch and backplane).
-				continue
-			}
-
-			// Interface base name matches, not looking for VIF. Pass.
-			return xpath.NewBoolDatum(true)
-		}
-
-		// All VIFs are L3.
-		vifs := intf.XChildren(vifFilter, xutils.Sorted)
-		for _, vif := range vifs {
-			if vif.XValue() == vifVal {
-				// Base interface and VIF both match. Pass.
-				return xpath.NewBoolDatum(true)
-			}
-		}
-
-		// Matched on base interface name, so if no matching VIF, we're done.
+	// Function has flexibility to be applied to '.' or any other node,
+	// rather than just the current node, but should only be applied to
+	// a single node.  So, if nodeset is empty or has multiple entries,
+	// return false.
+	ns0 := args[0].Nodeset("interface-in-routing-instance()")
+	if len(ns0) != 1 {
 		return xpath.NewBoolDatum(false)
 	}
+	vrfName := args[1].String("interface-in-routing-instance()")
 
-	return xpath.NewBoolDatum(false)
+	instance, ok := getRoutingInstance(ns0[0])
+	return xpath.NewBoolDatum(ok && instance == vrfName)
 }
 
-func parseInterfaceName(intfName string) (string, bool, string) {
-
-	intfParts := strings.Split(intfName, ".")
-	intfVal := intfParts[0]
+// sameRoutingInstance - implementation of
+// same-routing-instance(<nodeset>, <nodeset>)
+// Matches if both nodes name existing interfaces or VIFs, resolved as for
+// is-interface-leafref(), that are in the same routing instance.  Typical
+// use is checking a feature's interface is in the same VRF as another
+// interface it depends on, eg:
+//
+//   configd:must "same-routing-instance(., ../source-interface)"
+//
+func sameRoutingInstance(
+	args []xpath.Datum,
+) (retBool xpath.Datum) {
 
-	vifVal := ""
-	if len(intfParts) == 2 {
-		vifVal = intfParts[1]
-		return intfVal, true, vifVal
+	ns0 := args[0].Nodeset("same-routing-instance()")
+	ns1 := args[1].Nodeset("same-routing-instance()")
+	if len(ns0) != 1 || len(ns1) != 1 {
+		return xpath.NewBoolDatum(false)
 	}
 
-	return intfVal, false, "not-VIF-interface"
+	instance0, ok0 := getRoutingInstance(ns0[0])
+	instance1, ok1 := getRoutingInstance(ns1[0])
+	return xpath.NewBoolDatum(ok0 && ok1 && instance0 == instance1)
 }
 
-func ignoreInterface(intfType string, filter []string) bool {
-	for _, name := range filter {
-		if intfType == name {
-			return true
-		}
+// getRoutingInstance - return the routing instance of the interface named
+// by the node's value, or false if there is no such interface.
+func getRoutingInstance(srcNode xutils.XpathNode) (string, bool) {
+	root := srcNode.XRoot()
+	if _, ok := common.GetInterfaceNode(
+		root, srcNode.XValue(), []string{}); !ok {
+		return "", false
 	}
-
-	return false
+	return common.GetInterfaceRoutingInstance(root, srcNode.XValue()), true
 }
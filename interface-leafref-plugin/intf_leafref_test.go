@@ -159,3 +159,214 @@ func TestInterfaceLeafrefMustReplacement(t *testing.T) {
 		})
 	}
 }
+
+type interfaceStateTest struct {
+	name,
+	ref string
+	expInState,
+	expOnlyInState bool
+}
+
+func TestInterfaceExistsInState(t *testing.T) {
+
+	tests := []interfaceStateTest{
+		{
+			name:           "Configured interface",
+			ref:            "dp0s1",
+			expInState:     true,
+			expOnlyInState: false,
+		},
+		{
+			name:           "Configured interface also in state",
+			ref:            "dp0s2",
+			expInState:     true,
+			expOnlyInState: false,
+		},
+		{
+			name:           "State only interface",
+			ref:            "dp0s3",
+			expInState:     true,
+			expOnlyInState: true,
+		},
+		{
+			name:           "State only VIF on configured interface",
+			ref:            "dp0s1.20",
+			expInState:     true,
+			expOnlyInState: true,
+		},
+		{
+			name:           "Configured VIF",
+			ref:            "dp0s1.10",
+			expInState:     true,
+			expOnlyInState: false,
+		},
+		{
+			name:           "Unknown interface",
+			ref:            "dp0s999",
+			expInState:     false,
+			expOnlyInState: false,
+		},
+		{
+			name:           "Unknown VIF",
+			ref:            "dp0s1.999",
+			expInState:     false,
+			expOnlyInState: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testTree := xpathtest.CreateTree(t,
+				[]xutils.PathType{
+					// configured
+					{"interfaces", "dataplane/tagnode+dp0s1", "vif/tagnode+10"},
+					{"interfaces", "dataplane/tagnode+dp0s2", "mtu+1500"},
+					// state
+					{"interfaces", "dataplane/tagnode+dp0s2", "!oper-status+up"},
+					{"interfaces", "!dataplane/tagnode+dp0s3"},
+					{"interfaces", "dataplane/tagnode+dp0s1",
+						"!vif/tagnode+20"},
+					// test leafref
+					{"feature", "intf-ref+" + test.ref},
+				})
+
+			testNode := testTree.FindFirstNode(
+				xutils.NewPathType("/feature/intf-ref"))
+			ns := xpath.NewNodesetDatum([]xutils.XpathNode{testNode})
+
+			actInState := interfaceExistsInState([]xpath.Datum{ns})
+			if actInState.Boolean("(not used)") != test.expInState {
+				t.Fatalf("Unexpected in state result for %s: exp %t\n",
+					test.name, test.expInState)
+			}
+
+			actOnlyInState := interfaceExistsOnlyInState([]xpath.Datum{ns})
+			if actOnlyInState.Boolean("(not used)") != test.expOnlyInState {
+				t.Fatalf("Unexpected only in state result for %s: exp %t\n",
+					test.name, test.expOnlyInState)
+			}
+		})
+	}
+}
+
+type routingInstanceTest struct {
+	name,
+	ref,
+	otherRef,
+	vrf string
+	expInVrf,
+	expSame bool
+}
+
+func TestRoutingInstanceMembership(t *testing.T) {
+
+	tests := []routingInstanceTest{
+		{
+			name:     "Unbound interfaces in default",
+			ref:      "dp0s1",
+			otherRef: "dp0s3",
+			vrf:      "default",
+			expInVrf: true,
+			expSame:  true,
+		},
+		{
+			name:     "Bound interface in its VRF",
+			ref:      "dp0s2",
+			otherRef: "dp0s1.10",
+			vrf:      "blue",
+			expInVrf: true,
+			expSame:  true,
+		},
+		{
+			name:     "Bound interface not in default",
+			ref:      "dp0s2",
+			otherRef: "dp0s1",
+			vrf:      "default",
+			expInVrf: false,
+			expSame:  false,
+		},
+		{
+			name:     "VIF in different VRF to unbound parent",
+			ref:      "dp0s1.10",
+			otherRef: "dp0s1",
+			vrf:      "blue",
+			expInVrf: true,
+			expSame:  false,
+		},
+		{
+			name:     "VIF in different VRF to bound parent",
+			ref:      "dp0s2.20",
+			otherRef: "dp0s2",
+			vrf:      "red",
+			expInVrf: true,
+			expSame:  false,
+		},
+		{
+			name:     "Unbound VIF on bound parent is in default",
+			ref:      "dp0s2.30",
+			otherRef: "dp0s3",
+			vrf:      "default",
+			expInVrf: true,
+			expSame:  true,
+		},
+		{
+			name:     "Bound but unconfigured interface",
+			ref:      "dp0s9",
+			otherRef: "dp0s9",
+			vrf:      "blue",
+			expInVrf: false,
+			expSame:  false,
+		},
+		{
+			name:     "Unknown VIF",
+			ref:      "dp0s1.99",
+			otherRef: "dp0s1",
+			vrf:      "default",
+			expInVrf: false,
+			expSame:  false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testTree := xpathtest.CreateTree(t,
+				[]xutils.PathType{
+					{"interfaces", "dataplane/tagnode+dp0s1", "vif/tagnode+10"},
+					{"interfaces", "dataplane/tagnode+dp0s2", "vif/tagnode+20"},
+					{"interfaces", "dataplane/tagnode+dp0s2", "vif/tagnode+30"},
+					{"interfaces", "dataplane/tagnode+dp0s3"},
+					{"routing", "routing-instance/instance-name+blue",
+						"interface/name+dp0s2"},
+					{"routing", "routing-instance/instance-name+blue",
+						"interface/name+dp0s1.10"},
+					{"routing", "routing-instance/instance-name+blue",
+						"interface/name+dp0s9"},
+					{"routing", "routing-instance/instance-name+red",
+						"interface/name+dp0s2.20"},
+					// test leafrefs
+					{"feature", "intf-ref+" + test.ref},
+					{"feature", "other-intf-ref+" + test.otherRef},
+				})
+
+			testNode := testTree.FindFirstNode(
+				xutils.NewPathType("/feature/intf-ref"))
+			ns := xpath.NewNodesetDatum([]xutils.XpathNode{testNode})
+			otherNode := testTree.FindFirstNode(
+				xutils.NewPathType("/feature/other-intf-ref"))
+			otherNs := xpath.NewNodesetDatum([]xutils.XpathNode{otherNode})
+
+			actInVrf := interfaceInRoutingInstance(
+				[]xpath.Datum{ns, xpath.NewLiteralDatum(test.vrf)})
+			if actInVrf.Boolean("(not used)") != test.expInVrf {
+				t.Fatalf("Unexpected in routing instance result for %s: "+
+					"exp %t\n", test.name, test.expInVrf)
+			}
+
+			actSame := sameRoutingInstance([]xpath.Datum{ns, otherNs})
+			if actSame.Boolean("(not used)") != test.expSame {
+				t.Fatalf("Unexpected same routing instance result for %s: "+
+					"exp %t\n", test.name, test.expSame)
+			}
+		})
+	}
+}
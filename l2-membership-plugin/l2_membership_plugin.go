@@ -0,0 +1,180 @@
+// Copyright (c) 2021, AT&T Intellectual Property Inc.
+// All rights reserved.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"github.com/danos/xpath-plugins/common"
+	"github.com/danos/yang/xpath"
+	"github.com/danos/yang/xpath/xutils"
+)
+
+var RegistrationData = []xpath.CustomFunctionInfo{
+	{
+		Name:          "verify-l2-membership",
+		FnPtr:         verifyL2Membership,
+		Args:          []xpath.DatumTypeChecker{xpath.TypeIsNodeset},
+		RetType:       xpath.TypeIsBool,
+		DefaultRetVal: xpath.NewBoolDatum(false),
+	},
+	{
+		Name:          "is-l2-group-member",
+		FnPtr:         isL2GroupMember,
+		Args:          []xpath.DatumTypeChecker{xpath.TypeIsNodeset},
+		RetType:       xpath.TypeIsBool,
+		DefaultRetVal: xpath.NewBoolDatum(false),
+	},
+}
+
+// Filters used to find required nodes. Values never change, so create once
+// and reuse.
+var addressFilter = common.GetFilter("address")
+var allChildrenFilter = common.GetFilter("*")
+var bridgeFilter = common.GetFilter("bridge")
+var bridgeGroupFilter = common.GetFilter("bridge-group")
+var switchFilter = common.GetFilter("switch")
+var switchGroupFilter = common.GetFilter("switch-group")
+var vifFilter = common.GetFilter("vif")
+
+// l2GroupType - a type of L2 group (bridge or switch) that interfaces and
+// VIFs can be members of, and how membership is configured on the member.
+type l2GroupType struct {
+	groupFilter  xutils.XFilter
+	memberFilter xutils.XFilter
+}
+
+var l2GroupTypes = []l2GroupType{
+	{
+		groupFilter:  bridgeGroupFilter,
+		memberFilter: bridgeFilter,
+	},
+	{
+		groupFilter:  switchGroupFilter,
+		memberFilter: switchFilter,
+	},
+}
+
+// getL2Groups - return the bridges and switches the interface or VIF is a
+// member of.
+func getL2Groups(intfNode xutils.XpathNode) []xutils.XpathNode {
+	var groups []xutils.XpathNode
+	for _, groupType := range l2GroupTypes {
+		groups = append(groups, common.GetDescendantNodesFromSingleNode(
+			intfNode, []xutils.XFilter{
+				groupType.groupFilter,
+				groupType.memberFilter,
+			})...)
+	}
+	return groups
+}
+
+// l2Membership - the L2 groups an interface or VIF is a member of, and
+// whether it has any L3 addresses.
+type l2Membership struct {
+	groups     []xutils.XpathNode
+	hasAddress bool
+}
+
+// getL2Memberships - index the bridge-group and switch-group membership of
+// every interface and VIF, of every type, by interface name (eg dp0s1 or
+// dp0s1.10).  Interfaces that are not members of any group are omitted.
+func getL2Memberships(intfsNode xutils.XpathNode) map[string]l2Membership {
+	memberships := make(map[string]l2Membership)
+
+	for _, intfNode := range intfsNode.XChildren(
+		allChildrenFilter, xutils.Unsorted) {
+		addL2Membership(memberships, intfNode)
+		for _, vifNode := range intfNode.XChildren(
+			vifFilter, xutils.Unsorted) {
+			addL2Membership(memberships, vifNode)
+		}
+	}
+
+	return memberships
+}
+
+func addL2Membership(
+	memberships map[string]l2Membership,
+	intfNode xutils.XpathNode,
+) {
+	groups := getL2Groups(intfNode)
+	if len(groups) == 0 {
+		return
+	}
+
+	intfName := common.GetInterfaceName(intfNode)
+	membership := memberships[intfName]
+	membership.groups = append(membership.groups, groups...)
+	if len(intfNode.XChildren(addressFilter, xutils.Unsorted)) != 0 {
+		membership.hasAddress = true
+	}
+	memberships[intfName] = membership
+}
+
+// verifyL2Membership - implementation of verify-l2-membership(<nodeset>)
+//
+// Applied to the interfaces container, so the membership of every
+// interface and VIF is indexed once per tree.  Every interface or VIF that
+// is a member of a bridge or switch (bridge-group/bridge or
+// switch-group/switch):
+//
+//   - must only be a member of one bridge or switch in total
+//   - must not have any L3 addresses
+//
+// A VIF's membership and addresses are independent of its parent's.
+//
+//   configd:must "verify-l2-membership(.)"
+//
+func verifyL2Membership(
+	args []xpath.Datum,
+) (retBool xpath.Datum) {
+
+	// Function has flexibility to be applied to '.' or any other node,
+	// rather than just the current node, but should only be applied to
+	// a single node.  So, if nodeset is empty or has multiple entries,
+	// return false.
+	ns0 := args[0].Nodeset("verify-l2-membership()")
+	if len(ns0) != 1 {
+		return xpath.NewBoolDatum(false)
+	}
+	intfsNode := ns0[0]
+
+	for _, membership := range getL2Memberships(intfsNode) {
+		if len(membership.groups) > 1 || membership.hasAddress {
+			return xpath.NewBoolDatum(false)
+		}
+	}
+	return xpath.NewBoolDatum(true)
+}
+
+// isL2GroupMember - implementation of is-l2-group-member(<nodeset>)
+//
+// Matches any interface or VIF, named as for is-interface-leafref(), that
+// is a member of a bridge or switch.  Complements is-l3-interface-leafref()
+// for features that need an L3 interface, eg:
+//
+//   configd:must "is-l3-interface-leafref(.) and not(is-l2-group-member(.))"
+//
+func isL2GroupMember(
+	args []xpath.Datum,
+) (retBool xpath.Datum) {
+
+	// Function has flexibility to be applied to '.' or any other node,
+	// rather than just the current node, but should only be applied to
+	// a single node.  So, if nodeset is empty or has multiple entries,
+	// return false.
+	ns0 := args[0].Nodeset("is-l2-group-member()")
+	if len(ns0) != 1 {
+		return xpath.NewBoolDatum(false)
+	}
+	srcNode := ns0[0]
+
+	intfNode, ok := common.GetInterfaceNode(
+		srcNode.XRoot(), srcNode.XValue(), nil)
+	if !ok {
+		return xpath.NewBoolDatum(false)
+	}
+	return xpath.NewBoolDatum(len(getL2Groups(intfNode)) != 0)
+}
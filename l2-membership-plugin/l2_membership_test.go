@@ -0,0 +1,247 @@
+// Copyright (c) 2021, AT&T Intellectual Property. All rights reserved.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"testing"
+
+	"github.com/danos/yang/xpath"
+	"github.com/danos/yang/xpath/xpathtest"
+	"github.com/danos/yang/xpath/xutils"
+)
+
+type l2MembershipTestSpec struct {
+	name      string
+	config    []xutils.PathType
+	startPath string
+	expResult bool
+}
+
+var l2Groups = []xutils.PathType{
+	{"interfaces", "bridge/tagnode+br0"},
+	{"interfaces", "bridge/tagnode+br1"},
+	{"interfaces", "switch/name+sw0"},
+}
+
+func TestL2Membership(t *testing.T) {
+
+	tests := []l2MembershipTestSpec{
+		{
+			name: "Not a member, with address - PASS",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "address@dhcp"},
+			},
+			startPath: "/interfaces",
+			expResult: true,
+		},
+		{
+			name: "Bridge member - PASS",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "bridge-group",
+					"bridge+br0"},
+			},
+			startPath: "/interfaces",
+			expResult: true,
+		},
+		{
+			name: "Switch member - PASS",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "switch-group",
+					"switch+sw0"},
+			},
+			startPath: "/interfaces",
+			expResult: true,
+		},
+		{
+			name: "Member of two bridges - FAIL",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "bridge-group",
+					"bridge+br0"},
+				{"interfaces", "dataplane/tagnode+dp0s1", "bridge-group",
+					"bridge+br1"},
+			},
+			startPath: "/interfaces",
+			expResult: false,
+		},
+		{
+			name: "Member of bridge and switch - FAIL",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "bridge-group",
+					"bridge+br0"},
+				{"interfaces", "dataplane/tagnode+dp0s1", "switch-group",
+					"switch+sw0"},
+			},
+			startPath: "/interfaces",
+			expResult: false,
+		},
+		{
+			name: "Bridge member with address - FAIL",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "bridge-group",
+					"bridge+br0"},
+				{"interfaces", "dataplane/tagnode+dp0s1", "address@dhcp"},
+			},
+			startPath: "/interfaces",
+			expResult: false,
+		},
+		{
+			name: "Bridge member with VIF address - PASS",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "bridge-group",
+					"bridge+br0"},
+				{"interfaces", "dataplane/tagnode+dp0s1", "vif/tagnode+10",
+					"address@dhcp"},
+			},
+			startPath: "/interfaces",
+			expResult: true,
+		},
+		{
+			name: "VIF and parent in different bridges - PASS",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "bridge-group",
+					"bridge+br0"},
+				{"interfaces", "dataplane/tagnode+dp0s1", "vif/tagnode+10",
+					"bridge-group", "bridge+br1"},
+			},
+			startPath: "/interfaces",
+			expResult: true,
+		},
+		{
+			name: "VIF switch member with address - FAIL",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "vif/tagnode+10",
+					"switch-group", "switch+sw0"},
+				{"interfaces", "dataplane/tagnode+dp0s1", "vif/tagnode+10",
+					"address@dhcp"},
+			},
+			startPath: "/interfaces",
+			expResult: false,
+		},
+		{
+			name: "Other interface type in two groups - FAIL",
+			config: []xutils.PathType{
+				{"interfaces", "vhost/name+vhost0", "bridge-group",
+					"bridge+br0"},
+				{"interfaces", "vhost/name+vhost0", "switch-group",
+					"switch+sw0"},
+			},
+			startPath: "/interfaces",
+			expResult: false,
+		},
+		{
+			name: "Several valid members - PASS",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "bridge-group",
+					"bridge+br0"},
+				{"interfaces", "dataplane/tagnode+dp0s2", "vif/tagnode+10",
+					"bridge-group", "bridge+br0"},
+				{"interfaces", "dataplane/tagnode+dp0s2", "address@dhcp"},
+				{"interfaces", "vhost/name+vhost0", "switch-group",
+					"switch+sw0"},
+			},
+			startPath: "/interfaces",
+			expResult: true,
+		},
+		{
+			name: "Later member with address - FAIL",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "bridge-group",
+					"bridge+br0"},
+				{"interfaces", "dataplane/tagnode+dp0s2", "bridge-group",
+					"bridge+br1"},
+				{"interfaces", "vhost/name+vhost0", "switch-group",
+					"switch+sw0"},
+				{"interfaces", "vhost/name+vhost0", "address@dhcp"},
+			},
+			startPath: "/interfaces",
+			expResult: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testTree := xpathtest.CreateTree(t,
+				append(test.config, l2Groups...))
+
+			testNode := testTree.FindFirstNode(
+				xutils.NewPathType(test.startPath))
+			ns := xpath.NewNodesetDatum([]xutils.XpathNode{testNode})
+
+			actResult :=
+				verifyL2Membership([]xpath.Datum{ns}).Boolean(
+					"(unused value)")
+			if test.expResult != actResult {
+				t.Fatalf("Unexpected result for %s: exp %t, got %t\n",
+					test.name, test.expResult, actResult)
+			}
+		})
+	}
+}
+
+func TestIsL2GroupMember(t *testing.T) {
+
+	config := []xutils.PathType{
+		{"interfaces", "dataplane/tagnode+dp0s1", "bridge-group",
+			"bridge+br0"},
+		{"interfaces", "dataplane/tagnode+dp0s1", "vif/tagnode+10"},
+		{"interfaces", "dataplane/tagnode+dp0s2", "vif/tagnode+20",
+			"switch-group", "switch+sw0"},
+		{"interfaces", "dataplane/tagnode+dp0s3"},
+	}
+
+	tests := []l2MembershipTestSpec{
+		{
+			name:      "Bridge member",
+			config:    append(config, xutils.PathType{"feature", "ref+dp0s1"}),
+			startPath: "/feature/ref",
+			expResult: true,
+		},
+		{
+			name: "VIF of bridge member",
+			config: append(config,
+				xutils.PathType{"feature", "ref+dp0s1.10"}),
+			startPath: "/feature/ref",
+			expResult: false,
+		},
+		{
+			name:      "Parent of switch member VIF",
+			config:    append(config, xutils.PathType{"feature", "ref+dp0s2"}),
+			startPath: "/feature/ref",
+			expResult: false,
+		},
+		{
+			name: "Switch member VIF",
+			config: append(config,
+				xutils.PathType{"feature", "ref+dp0s2.20"}),
+			startPath: "/feature/ref",
+			expResult: true,
+		},
+		{
+			name:      "Not a member",
+			config:    append(config, xutils.PathType{"feature", "ref+dp0s3"}),
+			startPath: "/feature/ref",
+			expResult: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testTree := xpathtest.CreateTree(t,
+				append(test.config, l2Groups...))
+
+			testNode := testTree.FindFirstNode(
+				xutils.NewPathType(test.startPath))
+			ns := xpath.NewNodesetDatum([]xutils.XpathNode{testNode})
+
+			actResult :=
+				isL2GroupMember([]xpath.Datum{ns}).Boolean(
+					"(unused value)")
+			if test.expResult != actResult {
+				t.Fatalf("Unexpected result for %s: exp %t, got %t\n",
+					test.name, test.expResult, actResult)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright (c) 2021, AT&T Intellectual Property Inc.
+// All rights reserved.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"github.com/danos/xpath-plugins/common"
+	"github.com/danos/yang/xpath"
+	"github.com/danos/yang/xpath/xutils"
+)
+
+var RegistrationData = []xpath.CustomFunctionInfo{
+	{
+		Name:          "verify-nat-rule-unique",
+		FnPtr:         verifyNatRuleUnique,
+		Args:          []xpath.DatumTypeChecker{xpath.TypeIsNodeset},
+		RetType:       xpath.TypeIsBool,
+		DefaultRetVal: xpath.NewBoolDatum(false),
+	},
+	{
+		Name:          "verify-nat-translation-pool",
+		FnPtr:         verifyNatTranslationPool,
+		Args:          []xpath.DatumTypeChecker{xpath.TypeIsNodeset},
+		RetType:       xpath.TypeIsBool,
+		DefaultRetVal: xpath.NewBoolDatum(false),
+	},
+}
+
+// Translation address for source rules that uses the address of the
+// outbound interface rather than a pool.
+const masqueradeAddress = "masquerade"
+
+// Filters used to find required nodes. Values never change, so create once
+// and reuse.
+var addressFilter = common.GetFilter("address")
+var destinationFilter = common.GetFilter("destination")
+var inboundInterfaceFilter = common.GetFilter("inbound-interface")
+var natFilter = common.GetFilter("nat")
+var outboundInterfaceFilter = common.GetFilter("outbound-interface")
+var ruleFilter = common.GetFilter("rule")
+var serviceFilter = common.GetFilter("service")
+var sourceFilter = common.GetFilter("source")
+var translationFilter = common.GetFilter("translation")
+
+// natDirection - per-direction details of where rules are and which
+// interface leaf they use.
+type natDirection struct {
+	name       string
+	filter     xutils.XFilter
+	intfFilter xutils.XFilter
+}
+
+var natDirections = []natDirection{
+	{
+		name:       "source",
+		filter:     sourceFilter,
+		intfFilter: outboundInterfaceFilter,
+	},
+	{
+		name:       "destination",
+		filter:     destinationFilter,
+		intfFilter: inboundInterfaceFilter,
+	},
+}
+
+// natDirectionIntf - the rules on an interface in a given direction.
+type natDirectionIntf struct {
+	direction string
+	intf      string
+}
+
+// natRule - the details of a single source or destination rule needed to
+// check it against all other rules.
+type natRule struct {
+	direction string
+	number    string
+	intf      string
+	pool      common.AddressRange
+	hasPool   bool
+	poolValid bool
+}
+
+// getNatRules - index every source and destination rule under the nat
+// container in a single walk, so that all rules can then be checked
+// against each other without a separate XPath search per pair of rules.
+func getNatRules(natNode xutils.XpathNode) []natRule {
+	var rules []natRule
+	for _, dir := range natDirections {
+		ruleNodes := common.GetDescendantNodesFromSingleNode(
+			natNode, []xutils.XFilter{dir.filter, ruleFilter})
+		for _, ruleNode := range ruleNodes {
+			rule := natRule{
+				direction: dir.name,
+				number:    ruleNode.XValue(),
+				poolValid: true,
+			}
+			rule.intf, _ = common.GetSingleChildValue(
+				ruleNode, dir.intfFilter)
+			addrNodes := common.GetDescendantNodesFromSingleNode(
+				ruleNode, []xutils.XFilter{translationFilter, addressFilter})
+			if len(addrNodes) == 1 &&
+				addrNodes[0].XValue() != masqueradeAddress {
+				rule.hasPool = true
+				rule.pool, rule.poolValid =
+					common.ParseAddressPrefixOrRange(addrNodes[0].XValue())
+			}
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// verifyNatRuleUnique - implementation of verify-nat-rule-unique(<nodeset>)
+//
+// Applied once to the service nat container, and checks every source and
+// destination rule.  Replaces per-rule must statements of the form (for
+// source rules):
+//
+//   must "not(../../destination/rule[tagnode = current()/tagnode])";
+//   must "not(outbound-interface) or " +
+//        "/interfaces/*/*[(local-name(.) = 'tagnode' or " +
+//        "local-name(.) = 'ifname') and . = current()/outbound-interface]";
+//
+// ie rule numbers must not be shared between source and destination
+// rules, and the outbound (source) or inbound (destination) interface, if
+// set, must exist.  Implemented as:
+//
+//   configd:must "verify-nat-rule-unique(.)"
+//
+func verifyNatRuleUnique(
+	args []xpath.Datum,
+) (retBool xpath.Datum) {
+
+	// Function has flexibility to be applied to '.' or any other node,
+	// rather than just the current node, but should only be applied to
+	// a single node.  So, if nodeset is empty or has multiple entries,
+	// return false.
+	ns0 := args[0].Nodeset("verify-nat-rule-unique()")
+	if len(ns0) != 1 {
+		return xpath.NewBoolDatum(false)
+	}
+	natNode := ns0[0]
+	root := natNode.XRoot()
+
+	// Rule numbers are unique within each direction, so a number seen
+	// with a different direction is shared.  Interfaces are often used by
+	// many rules, so are only looked up once each.
+	ruleDirections := make(map[string]string)
+	intfExists := make(map[string]bool)
+	for _, rule := range getNatRules(natNode) {
+		if dir, ok := ruleDirections[rule.number]; ok &&
+			dir != rule.direction {
+			return xpath.NewBoolDatum(false)
+		}
+		ruleDirections[rule.number] = rule.direction
+
+		if rule.intf == "" || intfExists[rule.intf] {
+			continue
+		}
+		if _, ok := common.GetInterfaceNode(
+			root, rule.intf, []string{}); !ok {
+			return xpath.NewBoolDatum(false)
+		}
+		intfExists[rule.intf] = true
+	}
+
+	return xpath.NewBoolDatum(true)
+}
+
+// verifyNatTranslationPool - implementation of
+// verify-nat-translation-pool(<nodeset>)
+//
+// Applied once to the service nat container, and checks every source and
+// destination rule.  Each translation address that is set and isn't
+// 'masquerade' must be a valid address, prefix or address range, and must
+// not overlap the translation address of any other rule in the same
+// direction on the same interface.  Implemented as:
+//
+//   configd:must "verify-nat-translation-pool(.)"
+//
+func verifyNatTranslationPool(
+	args []xpath.Datum,
+) (retBool xpath.Datum) {
+
+	// Function has flexibility to be applied to '.' or any other node,
+	// rather than just the current node, but should only be applied to
+	// a single node.  So, if nodeset is empty or has multiple entries,
+	// return false.
+	ns0 := args[0].Nodeset("verify-nat-translation-pool()")
+	if len(ns0) != 1 {
+		return xpath.NewBoolDatum(false)
+	}
+	natNode := ns0[0]
+
+	// Group pools by direction and interface, as only pools in the same
+	// group can conflict.
+	pools := make(map[natDirectionIntf][]common.AddressRange)
+	for _, rule := range getNatRules(natNode) {
+		if !rule.hasPool {
+			continue
+		}
+		if !rule.poolValid {
+			return xpath.NewBoolDatum(false)
+		}
+		key := natDirectionIntf{direction: rule.direction, intf: rule.intf}
+		pools[key] = append(pools[key], rule.pool)
+	}
+
+	for _, groupPools := range pools {
+		if common.AnyRangesOverlap(groupPools) {
+			return xpath.NewBoolDatum(false)
+		}
+	}
+
+	return xpath.NewBoolDatum(true)
+}
@@ -0,0 +1,234 @@
+// Copyright (c) 2021, AT&T Intellectual Property. All rights reserved.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"testing"
+
+	"github.com/danos/yang/xpath"
+	"github.com/danos/yang/xpath/xpathtest"
+	"github.com/danos/yang/xpath/xutils"
+)
+
+type natTestSpec struct {
+	name      string
+	config    []xutils.PathType
+	startPath string
+	expResult bool
+}
+
+var natInterfaces = []xutils.PathType{
+	{"interfaces", "dataplane/tagnode+dp0s1"},
+	{"interfaces", "dataplane/tagnode+dp0s2", "vif/tagnode+10"},
+}
+
+func TestNatRuleUnique(t *testing.T) {
+
+	tests := []natTestSpec{
+		{
+			name: "Distinct rule numbers - PASS",
+			config: []xutils.PathType{
+				{"service", "nat", "source", "rule/tagnode+10",
+					"outbound-interface+dp0s1"},
+				{"service", "nat", "destination", "rule/tagnode+20",
+					"inbound-interface+dp0s2.10"},
+			},
+			startPath: "/service/nat",
+			expResult: true,
+		},
+		{
+			name: "Rule number in both directions - FAIL",
+			config: []xutils.PathType{
+				{"service", "nat", "source", "rule/tagnode+10",
+					"outbound-interface+dp0s1"},
+				{"service", "nat", "destination", "rule/tagnode+10",
+					"inbound-interface+dp0s1"},
+			},
+			startPath: "/service/nat",
+			expResult: false,
+		},
+		{
+			name: "No interface - PASS",
+			config: []xutils.PathType{
+				{"service", "nat", "destination", "rule/tagnode+10",
+					"translation", "address+10.0.0.1"},
+			},
+			startPath: "/service/nat",
+			expResult: true,
+		},
+		{
+			name: "Unknown outbound interface - FAIL",
+			config: []xutils.PathType{
+				{"service", "nat", "source", "rule/tagnode+10",
+					"outbound-interface+dp0s9"},
+			},
+			startPath: "/service/nat",
+			expResult: false,
+		},
+		{
+			name: "Unknown inbound VIF - FAIL",
+			config: []xutils.PathType{
+				{"service", "nat", "destination", "rule/tagnode+10",
+					"inbound-interface+dp0s2.20"},
+			},
+			startPath: "/service/nat",
+			expResult: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testTree := xpathtest.CreateTree(t,
+				append(test.config, natInterfaces...))
+
+			testNode := testTree.FindFirstNode(
+				xutils.NewPathType(test.startPath))
+			ns := xpath.NewNodesetDatum([]xutils.XpathNode{testNode})
+
+			actResult :=
+				verifyNatRuleUnique([]xpath.Datum{ns}).Boolean(
+					"(unused value)")
+			if test.expResult != actResult {
+				t.Fatalf("Unexpected result for %s: exp %t, got %t\n",
+					test.name, test.expResult, actResult)
+			}
+		})
+	}
+}
+
+func TestNatTranslationPool(t *testing.T) {
+
+	tests := []natTestSpec{
+		{
+			name: "No translation - PASS",
+			config: []xutils.PathType{
+				{"service", "nat", "source", "rule/tagnode+10",
+					"outbound-interface+dp0s1"},
+			},
+			startPath: "/service/nat",
+			expResult: true,
+		},
+		{
+			name: "Masquerade on shared interface - PASS",
+			config: []xutils.PathType{
+				{"service", "nat", "source", "rule/tagnode+10",
+					"outbound-interface+dp0s1"},
+				{"service", "nat", "source", "rule/tagnode+10",
+					"translation", "address+masquerade"},
+				{"service", "nat", "source", "rule/tagnode+20",
+					"outbound-interface+dp0s1"},
+				{"service", "nat", "source", "rule/tagnode+20",
+					"translation", "address+masquerade"},
+			},
+			startPath: "/service/nat",
+			expResult: true,
+		},
+		{
+			name: "Invalid translation range - FAIL",
+			config: []xutils.PathType{
+				{"service", "nat", "source", "rule/tagnode+10",
+					"outbound-interface+dp0s1"},
+				{"service", "nat", "source", "rule/tagnode+10",
+					"translation", "address+10.0.0.9-10.0.0.1"},
+			},
+			startPath: "/service/nat",
+			expResult: false,
+		},
+		{
+			name: "Disjoint pools on same interface - PASS",
+			config: []xutils.PathType{
+				{"service", "nat", "source", "rule/tagnode+10",
+					"outbound-interface+dp0s1"},
+				{"service", "nat", "source", "rule/tagnode+10",
+					"translation", "address+10.0.0.1-10.0.0.9"},
+				{"service", "nat", "source", "rule/tagnode+20",
+					"outbound-interface+dp0s1"},
+				{"service", "nat", "source", "rule/tagnode+20",
+					"translation", "address+10.0.0.10-10.0.0.19"},
+			},
+			startPath: "/service/nat",
+			expResult: true,
+		},
+		{
+			name: "Overlapping pools on same interface - FAIL",
+			config: []xutils.PathType{
+				{"service", "nat", "source", "rule/tagnode+10",
+					"outbound-interface+dp0s1"},
+				{"service", "nat", "source", "rule/tagnode+10",
+					"translation", "address+10.0.0.1-10.0.0.9"},
+				{"service", "nat", "source", "rule/tagnode+20",
+					"outbound-interface+dp0s1"},
+				{"service", "nat", "source", "rule/tagnode+20",
+					"translation", "address+10.0.0.5"},
+			},
+			startPath: "/service/nat",
+			expResult: false,
+		},
+		{
+			name: "Overlap between two of three pools - FAIL",
+			config: []xutils.PathType{
+				{"service", "nat", "source", "rule/tagnode+10",
+					"outbound-interface+dp0s1"},
+				{"service", "nat", "source", "rule/tagnode+10",
+					"translation", "address+10.0.0.32-10.0.0.47"},
+				{"service", "nat", "source", "rule/tagnode+20",
+					"outbound-interface+dp0s1"},
+				{"service", "nat", "source", "rule/tagnode+20",
+					"translation", "address+10.0.0.1-10.0.0.9"},
+				{"service", "nat", "source", "rule/tagnode+30",
+					"outbound-interface+dp0s1"},
+				{"service", "nat", "source", "rule/tagnode+30",
+					"translation", "address+10.0.0.40-10.0.0.50"},
+			},
+			startPath: "/service/nat",
+			expResult: false,
+		},
+		{
+			name: "Overlapping pools on different interfaces - PASS",
+			config: []xutils.PathType{
+				{"service", "nat", "source", "rule/tagnode+10",
+					"outbound-interface+dp0s1"},
+				{"service", "nat", "source", "rule/tagnode+10",
+					"translation", "address+10.0.0.1-10.0.0.9"},
+				{"service", "nat", "source", "rule/tagnode+20",
+					"outbound-interface+dp0s2.10"},
+				{"service", "nat", "source", "rule/tagnode+20",
+					"translation", "address+10.0.0.5"},
+			},
+			startPath: "/service/nat",
+			expResult: true,
+		},
+		{
+			name: "Overlapping pools in different directions - PASS",
+			config: []xutils.PathType{
+				{"service", "nat", "source", "rule/tagnode+10",
+					"translation", "address+10.0.0.1-10.0.0.9"},
+				{"service", "nat", "destination", "rule/tagnode+20",
+					"translation", "address+10.0.0.5"},
+			},
+			startPath: "/service/nat",
+			expResult: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testTree := xpathtest.CreateTree(t,
+				append(test.config, natInterfaces...))
+
+			testNode := testTree.FindFirstNode(
+				xutils.NewPathType(test.startPath))
+			ns := xpath.NewNodesetDatum([]xutils.XpathNode{testNode})
+
+			actResult :=
+				verifyNatTranslationPool([]xpath.Datum{ns}).Boolean(
+					"(unused value)")
+			if test.expResult != actResult {
+				t.Fatalf("Unexpected result for %s: exp %t, got %t\n",
+					test.name, test.expResult, actResult)
+			}
+		})
+	}
+}
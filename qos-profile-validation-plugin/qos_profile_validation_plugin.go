@@ -6,6 +6,10 @@
 package main
 
 import (
+	"sort"
+	"strconv"
+	"strings"
+
 	"github.com/danos/xpath-plugins/common"
 	"github.com/danos/yang/xpath"
 	"github.com/danos/yang/xpath/xutils"
@@ -26,14 +30,169 @@ var RegistrationData = []xpath.CustomFunctionInfo{
 		RetType:       xpath.TypeIsBool,
 		DefaultRetVal: xpath.NewBoolDatum(false),
 	},
+	{
+		Name:          "verify-pcp-to-queue-mappings",
+		FnPtr:         verifyPcpToQueueMappings,
+		Args:          []xpath.DatumTypeChecker{xpath.TypeIsNodeset},
+		RetType:       xpath.TypeIsBool,
+		DefaultRetVal: xpath.NewBoolDatum(false),
+	},
+	{
+		Name:          "verify-map-classification-not-mixed",
+		FnPtr:         verifyMapClassificationNotMixed,
+		Args:          []xpath.DatumTypeChecker{xpath.TypeIsNodeset},
+		RetType:       xpath.TypeIsBool,
+		DefaultRetVal: xpath.NewBoolDatum(false),
+	},
+	{
+		Name:          "verify-queue-references",
+		FnPtr:         verifyQueueReferences,
+		Args:          []xpath.DatumTypeChecker{xpath.TypeIsNodeset},
+		RetType:       xpath.TypeIsBool,
+		DefaultRetVal: xpath.NewBoolDatum(false),
+	},
+	{
+		Name:          "verify-dscp-group-coverage",
+		FnPtr:         verifyDscpGroupCoverage,
+		Args:          []xpath.DatumTypeChecker{xpath.TypeIsNodeset},
+		RetType:       xpath.TypeIsBool,
+		DefaultRetVal: xpath.NewBoolDatum(false),
+	},
+	{
+		Name:          "verify-dscp-group-full-coverage",
+		FnPtr:         verifyDscpGroupFullCoverage,
+		Args:          []xpath.DatumTypeChecker{xpath.TypeIsNodeset},
+		RetType:       xpath.TypeIsBool,
+		DefaultRetVal: xpath.NewBoolDatum(false),
+	},
+	{
+		Name:          "verify-shaper-rate-hierarchy",
+		FnPtr:         verifyShaperRateHierarchy,
+		Args:          []xpath.DatumTypeChecker{xpath.TypeIsNodeset},
+		RetType:       xpath.TypeIsBool,
+		DefaultRetVal: xpath.NewBoolDatum(false),
+	},
+	{
+		Name:          "verify-wred-thresholds",
+		FnPtr:         verifyWredThresholds,
+		Args:          []xpath.DatumTypeChecker{xpath.TypeIsNodeset},
+		RetType:       xpath.TypeIsBool,
+		DefaultRetVal: xpath.NewBoolDatum(false),
+	},
+	{
+		Name:          "is-qos-policy-leafref",
+		FnPtr:         isQosPolicyLeafref,
+		Args:          []xpath.DatumTypeChecker{xpath.TypeIsNodeset},
+		RetType:       xpath.TypeIsBool,
+		DefaultRetVal: xpath.NewBoolDatum(false),
+	},
+	{
+		Name:          "verify-vif-qos-attachment",
+		FnPtr:         verifyVifQosAttachment,
+		Args:          []xpath.DatumTypeChecker{xpath.TypeIsNodeset},
+		RetType:       xpath.TypeIsBool,
+		DefaultRetVal: xpath.NewBoolDatum(false),
+	},
+	{
+		Name:          "qos-unused-profiles",
+		FnPtr:         qosUnusedProfiles,
+		Args:          []xpath.DatumTypeChecker{xpath.TypeIsNodeset},
+		RetType:       xpath.TypeIsLiteral,
+		DefaultRetVal: xpath.NewLiteralDatum(""),
+	},
+	{
+		Name:          "qos-unused-dscp-groups",
+		FnPtr:         qosUnusedDscpGroups,
+		Args:          []xpath.DatumTypeChecker{xpath.TypeIsNodeset},
+		RetType:       xpath.TypeIsLiteral,
+		DefaultRetVal: xpath.NewLiteralDatum(""),
+	},
+	{
+		Name:          "qos-profile-signature",
+		FnPtr:         qosProfileSignature,
+		Args:          []xpath.DatumTypeChecker{xpath.TypeIsNodeset},
+		RetType:       xpath.TypeIsLiteral,
+		DefaultRetVal: xpath.NewLiteralDatum(""),
+	},
+	{
+		Name:          "verify-ingress-map-coverage",
+		FnPtr:         verifyIngressMapCoverage,
+		Args:          []xpath.DatumTypeChecker{xpath.TypeIsNodeset},
+		RetType:       xpath.TypeIsBool,
+		DefaultRetVal: xpath.NewBoolDatum(false),
+	},
+	{
+		Name:          "verify-ingress-map-designations",
+		FnPtr:         verifyIngressMapDesignations,
+		Args:          []xpath.DatumTypeChecker{xpath.TypeIsNodeset},
+		RetType:       xpath.TypeIsBool,
+		DefaultRetVal: xpath.NewBoolDatum(false),
+	},
+	{
+		Name:          "verify-ingress-map-system-default-unique",
+		FnPtr:         verifyIngressMapSystemDefaultUnique,
+		Args:          []xpath.DatumTypeChecker{xpath.TypeIsNodeset},
+		RetType:       xpath.TypeIsBool,
+		DefaultRetVal: xpath.NewBoolDatum(false),
+	},
 }
 
+// Dataplane QoS limits.  Traffic classes are numbered from 0, as are queue
+// IDs, with a fixed number of queues available to each traffic class.
+const (
+	numTrafficClasses        = 4
+	maxQueuesPerTrafficClass = 8
+	maxQueueId               = numTrafficClasses*maxQueuesPerTrafficClass - 1
+	numPcpValues             = 8
+	maxQueueWeight           = 100
+	numDesignations          = 8
+)
+
 // Filters used to find required nodes. Values never change, so create once
 // and reuse.
-var idFilter = common.GetFilter("id")
-var groupNameFilter = common.GetFilter("group-name")
-var toFilter = common.GetFilter("to")
-var trafficClassFilter = common.GetFilter("traffic-class")
+//
+// Nodes defined by the QoS module, and the top-level policy container, are
+// qualified with the prefixes used by the original must statements so that
+// identically named nodes from other modules are not matched.
+var idFilter = common.GetPrefixedFilter("qos:id")
+var groupNameFilter = common.GetPrefixedFilter("qos:group-name")
+var toFilter = common.GetPrefixedFilter("qos:to")
+var trafficClassFilter = common.GetPrefixedFilter("qos:traffic-class")
+var bandwidthFilter = common.GetPrefixedFilter("qos:bandwidth")
+var burstFilter = common.GetPrefixedFilter("qos:burst")
+var classFilter = common.GetPrefixedFilter("qos:class")
+var defaultFilter = common.GetPrefixedFilter("qos:default")
+var designationFilter = common.GetPrefixedFilter("qos:designation")
+var dropPrecedenceFilter = common.GetPrefixedFilter("qos:drop-precedence")
+var dscpFilter = common.GetPrefixedFilter("qos:dscp")
+var dscpGroupFilter = common.GetPrefixedFilter("qos:dscp-group")
+var ingressMapFilter = common.GetPrefixedFilter("qos:ingress-map")
+var mapFilter = common.GetPrefixedFilter("qos:map")
+var markProbabilityFilter = common.GetPrefixedFilter("qos:mark-probability")
+var maxThresholdFilter = common.GetPrefixedFilter("qos:max-threshold")
+var minThresholdFilter = common.GetPrefixedFilter("qos:min-threshold")
+var nameFilter = common.GetPrefixedFilter("qos:name")
+var pcpFilter = common.GetPrefixedFilter("qos:pcp")
+var policyFilter = common.GetPrefixedFilter("policy:policy")
+var profileFilter = common.GetPrefixedFilter("qos:profile")
+var qosFilter = common.GetPrefixedFilter("qos:qos")
+var queueLimitFilter = common.GetPrefixedFilter("qos:queue-limit")
+var queueFilter = common.GetPrefixedFilter("qos:queue")
+var shaperFilter = common.GetPrefixedFilter("qos:shaper")
+var systemDefaultFilter = common.GetPrefixedFilter("qos:system-default")
+var weightFilter = common.GetPrefixedFilter("qos:weight")
+var wredMapFilter = common.GetPrefixedFilter("qos:wred-map")
+
+// Nodes outside the QoS module, or defined by more than one module (eg
+// policy containers on the different interface types), match any module.
+var allChildrenFilter = common.GetFilter("*")
+var groupFilter = common.GetFilter("group")
+var interfacesFilter = common.GetFilter("interfaces")
+var intfPolicyFilter = common.GetFilter("policy")
+var resourcesDscpGroupFilter = common.GetFilter("dscp-group")
+var resourcesFilter = common.GetFilter("resources")
+var speedFilter = common.GetFilter("speed")
+var vifFilter = common.GetFilter("vif")
 
 // verifyQueueIdAndTrafficClass
 //
@@ -91,8 +250,8 @@ func verifyQueueIdAndTrafficClass(
 	// Return true if we have any ingress-maps configured
 	mapNodes := common.GetDescendantNodesFromSingleNode(
 		root, []xutils.XFilter{
-			common.GetFilter("policy"),
-			common.GetFilter("ingress-map"),
+			policyFilter,
+			ingressMapFilter,
 		})
 	if mapNodes != nil && len(mapNodes) != 0 {
 		return xpath.NewBoolDatum(true)
@@ -109,15 +268,15 @@ func verifyQueueIdAndTrafficClass(
 	}
 
 	var reqValues = map[xutils.XFilter]string{
-		common.GetFilter("id"):            id,
-		common.GetFilter("traffic-class"): trafficClass,
+		idFilter:           id,
+		trafficClassFilter: trafficClass,
 	}
 
 	// Now look at the entries that need to match id/traffic-class.
 	qosNodes := common.GetDescendantNodesFromSingleNode(
 		root, []xutils.XFilter{
-			common.GetFilter("policy"),
-			common.GetFilter("qos"),
+			policyFilter,
+			qosFilter,
 		})
 	if qosNodes == nil || len(qosNodes) > 1 {
 		return xpath.NewBoolDatum(false)
@@ -127,28 +286,28 @@ func verifyQueueIdAndTrafficClass(
 	// Get local profiles, then queue children with matching required values.
 	localProfileNodes := common.GetDescendantNodesFromSingleNode(qosNode,
 		[]xutils.XFilter{
-			common.GetFilter("name"),
-			common.GetFilter("shaper"),
-			common.GetFilter("profile"),
+			nameFilter,
+			shaperFilter,
+			profileFilter,
 		})
 	localProfileQueueNodes := common.GetDescendantNodes(
 		localProfileNodes, []xutils.XFilter{
-			common.GetFilter("queue"),
+			queueFilter,
 		})
-	matchingLPQNodeCount := common.GetCountOfChildNodesWithRequiredValues(
-		localProfileQueueNodes, reqValues)
+	matchingLPQNodeCount := common.GetChildNodeMatchCounts(
+		localProfileQueueNodes, reqValues).Match
 
 	// Get global profiles, then queue children with matching required values.
 	globalProfileNodes := common.GetDescendantNodesFromSingleNode(qosNode,
 		[]xutils.XFilter{
-			common.GetFilter("profile"),
+			profileFilter,
 		})
 	globalProfileQueueNodes := common.GetDescendantNodes(
 		globalProfileNodes, []xutils.XFilter{
-			common.GetFilter("queue"),
+			queueFilter,
 		})
-	matchingGPQNodeCount := common.GetCountOfChildNodesWithRequiredValues(
-		globalProfileQueueNodes, reqValues)
+	matchingGPQNodeCount := common.GetChildNodeMatchCounts(
+		globalProfileQueueNodes, reqValues).Match
 
 	// count(name/shaper/profile) + count(profile) =
 	// count(n/s/p/q[match id and tc]) + count(profile/queue[match id and tc])
@@ -179,12 +338,48 @@ func verifyQueueIdAndTrafficClass(
 func verifyDscpGroupToQueueMappings(
 	args []xpath.Datum,
 ) (retBool xpath.Datum) {
+	return verifyMapEntryToQueueMappings(args,
+		"verify-dscp-group-to-queue-mappings", dscpGroupFilter,
+		groupNameFilter)
+}
+
+// verifyPcpToQueueMappings
+//
+// PCP equivalent of verifyDscpGroupToQueueMappings.  On each local and
+// global map pcp entry that the must is called on, we check that there is
+// an equivalent entry on all local and global profile maps.  As every map
+// is counted, this also fails if any profile classifies by DSCP group while
+// others classify by PCP.
+//
+// must "count(name/shaper/profile/map)
+//       + count(profile/map)
+//       = count(name/shaper/profile/map/pcp
+//             [id = current()/id and to = current()/to])
+//       + count(profile/map/pcp
+//             [id = current()/id and to = current()/to])"
+//
+func verifyPcpToQueueMappings(
+	args []xpath.Datum,
+) (retBool xpath.Datum) {
+	return verifyMapEntryToQueueMappings(args,
+		"verify-pcp-to-queue-mappings", pcpFilter, idFilter)
+}
+
+// verifyMapEntryToQueueMappings - common implementation for map entries
+// of any type (dscp-group, pcp), identified by entryFilter and keyed by
+// the leaf identified by keyFilter.
+func verifyMapEntryToQueueMappings(
+	args []xpath.Datum,
+	fnName string,
+	entryFilter xutils.XFilter,
+	keyFilter xutils.XFilter,
+) (retBool xpath.Datum) {
 
 	// Function has flexibility to be applied to '.' or any other node,
 	// rather than just the current node, but should only be applied to
 	// a single node.  So, if nodeset is empty or has multiple entries,
 	// return false.
-	ns0 := args[0].Nodeset("verify-dscp-group-to-queue-mappings")
+	ns0 := args[0].Nodeset(fnName)
 	if len(ns0) != 1 {
 		return xpath.NewBoolDatum(false)
 	}
@@ -192,9 +387,9 @@ func verifyDscpGroupToQueueMappings(
 	srcNode := ns0[0]
 	root := srcNode.XRoot()
 
-	// Get current()/group-name and current()/to
-	groupName, to, ok := "", "", true
-	if groupName, ok = common.GetSingleChildValue(srcNode, groupNameFilter); !ok {
+	// Get current()/<key> and current()/to
+	key, to, ok := "", "", true
+	if key, ok = common.GetSingleChildValue(srcNode, keyFilter); !ok {
 		return xpath.NewBoolDatum(false)
 	}
 	if to, ok = common.GetSingleChildValue(srcNode, toFilter); !ok {
@@ -202,55 +397,1217 @@ func verifyDscpGroupToQueueMappings(
 	}
 
 	var reqValues = map[xutils.XFilter]string{
-		common.GetFilter("to"):         to,
-		common.GetFilter("group-name"): groupName,
+		toFilter:  to,
+		keyFilter: key,
 	}
 
 	// Local and global profiles live under same root, so get that once.
 	qosNodes := common.GetDescendantNodesFromSingleNode(
 		root, []xutils.XFilter{
-			common.GetFilter("policy"),
-			common.GetFilter("qos"),
+			policyFilter,
+			qosFilter,
 		})
 	if qosNodes == nil || len(qosNodes) > 1 {
 		return xpath.NewBoolDatum(false)
 	}
 	qosNode := qosNodes[0]
 
-	// Get local maps, and dscp-group children with required values.
+	// Get local maps, and entry children with required values.
 	localMapNodes := common.GetDescendantNodesFromSingleNode(qosNode,
 		[]xutils.XFilter{
-			common.GetFilter("name"),
-			common.GetFilter("shaper"),
-			common.GetFilter("profile"),
-			common.GetFilter("map"),
-		})
-	localMapDscpGroupNodes := common.GetDescendantNodes(
-		localMapNodes, []xutils.XFilter{
-			common.GetFilter("dscp-group"),
+			nameFilter,
+			shaperFilter,
+			profileFilter,
+			mapFilter,
 		})
-	matchingLMDGNodeCount := common.GetCountOfChildNodesWithRequiredValues(
-		localMapDscpGroupNodes, reqValues)
+	localMapEntryNodes := common.GetDescendantNodes(
+		localMapNodes, []xutils.XFilter{entryFilter})
+	matchingLMENodeCount := common.GetChildNodeMatchCounts(
+		localMapEntryNodes, reqValues).Match
 
-	// Get global maps, and dscp-group children with required values.
+	// Get global maps, and entry children with required values.
 	globalMapNodes := common.GetDescendantNodesFromSingleNode(qosNode,
 		[]xutils.XFilter{
-			common.GetFilter("profile"),
-			common.GetFilter("map"),
+			profileFilter,
+			mapFilter,
 		})
-	globalMapDscpGroupNodes := common.GetDescendantNodes(
-		globalMapNodes, []xutils.XFilter{
-			common.GetFilter("dscp-group"),
-		})
-	matchingGMDGNodeCount := common.GetCountOfChildNodesWithRequiredValues(
-		globalMapDscpGroupNodes, reqValues)
+	globalMapEntryNodes := common.GetDescendantNodes(
+		globalMapNodes, []xutils.XFilter{entryFilter})
+	matchingGMENodeCount := common.GetChildNodeMatchCounts(
+		globalMapEntryNodes, reqValues).Match
 
 	// count(name/shaper/profile/map) + count(profile/map) =
-	// count(n/s/p/m/dscp-group[match group-name and to]) +
-	// count(profile/map/dscp-group[match group-name and to])
+	// count(n/s/p/m/<entry>[match key and to]) +
+	// count(profile/map/<entry>[match key and to])
 	if (len(localMapNodes) + len(globalMapNodes)) ==
-		(matchingLMDGNodeCount + matchingGMDGNodeCount) {
+		(matchingLMENodeCount + matchingGMENodeCount) {
 		return xpath.NewBoolDatum(true)
 	}
 	return xpath.NewBoolDatum(false)
 }
+
+// verifyMapClassificationNotMixed
+//
+// Applied to a (local or global) profile.  A profile classifies either by
+// DSCP group or by PCP, so its map entries must not include both dscp-group
+// and pcp entries.
+//
+//  must "not(map/dscp-group and map/pcp)"
+//
+func verifyMapClassificationNotMixed(
+	args []xpath.Datum,
+) (retBool xpath.Datum) {
+
+	// Function has flexibility to be applied to '.' or any other node,
+	// rather than just the current node, but should only be applied to
+	// a single node.  So, if nodeset is empty or has multiple entries,
+	// return false.
+	ns0 := args[0].Nodeset("verify-map-classification-not-mixed()")
+	if len(ns0) != 1 {
+		return xpath.NewBoolDatum(false)
+	}
+	profileNode := ns0[0]
+
+	dscpGroupNodes := common.GetDescendantNodesFromSingleNode(
+		profileNode, []xutils.XFilter{mapFilter, dscpGroupFilter})
+	pcpNodes := common.GetDescendantNodesFromSingleNode(
+		profileNode, []xutils.XFilter{mapFilter, pcpFilter})
+	if len(dscpGroupNodes) != 0 && len(pcpNodes) != 0 {
+		return xpath.NewBoolDatum(false)
+	}
+	return xpath.NewBoolDatum(true)
+}
+
+// verifyQueueReferences
+//
+// Checks that the references made by a profile queue (local or global) are
+// valid, ie:
+//
+//  - queue id is in the range supported by the dataplane;
+//  - traffic-class is in the range supported by the dataplane;
+//  - no more than maxQueuesPerTrafficClass queues in the profile share
+//    this queue's traffic-class;
+//  - if the queue has a weight, it is in the range supported by the
+//    dataplane, and the profile (pipe) defines the queue's traffic-class,
+//    as the weight only applies within the pipe's traffic-class;
+//  - if the profile (pipe) configures its own traffic-class entries, this
+//    queue's traffic-class is one of them, so its pipe rate is defined; and
+//  - the shaper(s) using this profile define this queue's traffic-class.
+//
+// For a local profile, the shaper is the one the profile is defined under.
+// A global profile is checked against every shaper that references it,
+// either as its default profile or from a class.
+//
+//  configd:must "verify-queue-references(.)"
+//
+func verifyQueueReferences(
+	args []xpath.Datum,
+) (retBool xpath.Datum) {
+
+	// Function has flexibility to be applied to '.' or any other node,
+	// rather than just the current node, but should only be applied to
+	// a single node.  So, if nodeset is empty or has multiple entries,
+	// return false.
+	ns0 := args[0].Nodeset("verify-queue-references()")
+	if len(ns0) != 1 {
+		return xpath.NewBoolDatum(false)
+	}
+	queueNode := ns0[0]
+	profileNode := queueNode.XParent()
+
+	// Get current()/id and current()/traffic-class, and check both are
+	// within range.
+	id, trafficClass, ok := "", "", true
+	if id, ok = common.GetSingleChildValue(queueNode, idFilter); !ok {
+		return xpath.NewBoolDatum(false)
+	}
+	if trafficClass, ok = common.GetSingleChildValue(
+		queueNode, trafficClassFilter); !ok {
+		return xpath.NewBoolDatum(false)
+	}
+	if !isUintInRange(id, maxQueueId) ||
+		!isUintInRange(trafficClass, numTrafficClasses-1) {
+		return xpath.NewBoolDatum(false)
+	}
+
+	// count(../queue[traffic-class = current()/traffic-class]) <= max
+	if common.GetChildNodeMatchCounts(
+		profileNode.XChildren(queueFilter, xutils.Unsorted),
+		map[xutils.XFilter]string{trafficClassFilter: trafficClass},
+	).Match > maxQueuesPerTrafficClass {
+		return xpath.NewBoolDatum(false)
+	}
+
+	// Profile (pipe) traffic-class must be defined if the queue has a
+	// weight, or if the pipe defines any.
+	weight, hasWeight := common.GetSingleChildValue(queueNode, weightFilter)
+	if hasWeight && !isQueueWeightValid(weight) {
+		return xpath.NewBoolDatum(false)
+	}
+	if hasWeight ||
+		len(profileNode.XChildren(trafficClassFilter, xutils.Unsorted)) > 0 {
+		if !isTrafficClassDefined(profileNode, trafficClass) {
+			return xpath.NewBoolDatum(false)
+		}
+	}
+
+	// Each shaper using this profile must define the traffic-class.
+	for _, shaperNode := range getShapersUsingProfile(profileNode) {
+		if !isTrafficClassDefined(shaperNode, trafficClass) {
+			return xpath.NewBoolDatum(false)
+		}
+	}
+
+	return xpath.NewBoolDatum(true)
+}
+
+// isUintInRange - true if value is an unsigned integer no greater than max.
+func isUintInRange(value string, max uint64) bool {
+	num, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return false
+	}
+	return num <= max
+}
+
+// isQueueWeightValid - true if weight is a WRR weight the dataplane
+// supports, ie from 1 to maxQueueWeight.
+func isQueueWeightValid(weight string) bool {
+	num, err := strconv.ParseUint(weight, 10, 64)
+	if err != nil {
+		return false
+	}
+	return num > 0 && num <= maxQueueWeight
+}
+
+// isTrafficClassDefined - returns true if one of the traffic-class list
+// entries of node has the given traffic class ID.
+func isTrafficClassDefined(node xutils.XpathNode, trafficClass string) bool {
+	for _, tcNode := range node.XChildren(trafficClassFilter, xutils.Unsorted) {
+		if tcNode.XValue() == trafficClass {
+			return true
+		}
+	}
+	return false
+}
+
+// getShapersUsingProfile - returns the shaper a local profile is defined
+// under, or all shapers that reference a global profile.
+func getShapersUsingProfile(profileNode xutils.XpathNode) []xutils.XpathNode {
+	parent := profileNode.XParent()
+	if parent.XName() == "shaper" {
+		return []xutils.XpathNode{parent}
+	}
+
+	profileName := profileNode.XValue()
+	shaperNodes := common.GetDescendantNodesFromSingleNode(
+		profileNode.XRoot(), []xutils.XFilter{
+			policyFilter,
+			qosFilter,
+			nameFilter,
+			shaperFilter,
+		})
+
+	var usingShapers []xutils.XpathNode
+	for _, shaperNode := range shaperNodes {
+		if defProfile, ok := common.GetSingleChildValue(
+			shaperNode, defaultFilter); ok && defProfile == profileName {
+			usingShapers = append(usingShapers, shaperNode)
+			continue
+		}
+		if common.GetChildNodeMatchCounts(
+			shaperNode.XChildren(classFilter, xutils.Unsorted),
+			map[xutils.XFilter]string{profileFilter: profileName},
+		).Match > 0 {
+			usingShapers = append(usingShapers, shaperNode)
+		}
+	}
+	return usingShapers
+}
+
+// verifyDscpGroupCoverage
+//
+// Applied to a (local or global) profile map.  Expands the DSCP values of
+// every dscp-group defined under /resources/group, and checks that no DSCP
+// value is matched by more than one of the dscp-groups used in the map.
+//
+//  configd:must "verify-dscp-group-coverage(.)"
+//
+func verifyDscpGroupCoverage(
+	args []xpath.Datum,
+) (retBool xpath.Datum) {
+	return verifyDscpGroupCoverageInternal(
+		args, "verify-dscp-group-coverage()", false)
+}
+
+// verifyDscpGroupFullCoverage
+//
+// As verifyDscpGroupCoverage, but additionally requires that every DSCP
+// value (0-63) is matched by one of the dscp-groups used in the map.
+//
+//  configd:must "verify-dscp-group-full-coverage(.)"
+//
+func verifyDscpGroupFullCoverage(
+	args []xpath.Datum,
+) (retBool xpath.Datum) {
+	return verifyDscpGroupCoverageInternal(
+		args, "verify-dscp-group-full-coverage()", true)
+}
+
+func verifyDscpGroupCoverageInternal(
+	args []xpath.Datum,
+	fnName string,
+	fullCoverage bool,
+) (retBool xpath.Datum) {
+
+	// Function has flexibility to be applied to '.' or any other node,
+	// rather than just the current node, but should only be applied to
+	// a single node.  So, if nodeset is empty or has multiple entries,
+	// return false.
+	ns0 := args[0].Nodeset(fnName)
+	if len(ns0) != 1 {
+		return xpath.NewBoolDatum(false)
+	}
+	mapNode := ns0[0]
+
+	groupNodes := getDscpGroupNodes(mapNode.XRoot())
+
+	// Record which group each DSCP value is mapped by.  A group referenced
+	// by the map but not defined, or with values that can't be parsed,
+	// can't be checked, so fails.
+	var dscpOwners [common.NumDscpValues]string
+	for _, mapGroupNode := range mapNode.XChildren(
+		dscpGroupFilter, xutils.Unsorted) {
+		groupName, ok := common.GetSingleChildValue(
+			mapGroupNode, groupNameFilter)
+		if !ok {
+			return xpath.NewBoolDatum(false)
+		}
+		groupNode, ok := groupNodes[groupName]
+		if !ok {
+			return xpath.NewBoolDatum(false)
+		}
+		values, ok := getDscpGroupValues(groupNode)
+		if !ok {
+			return xpath.NewBoolDatum(false)
+		}
+		for _, value := range values {
+			if dscpOwners[value] != "" && dscpOwners[value] != groupName {
+				return xpath.NewBoolDatum(false)
+			}
+			dscpOwners[value] = groupName
+		}
+	}
+
+	if fullCoverage {
+		for _, owner := range dscpOwners {
+			if owner == "" {
+				return xpath.NewBoolDatum(false)
+			}
+		}
+	}
+
+	return xpath.NewBoolDatum(true)
+}
+
+// getDscpGroupNodes - returns each dscp-group defined under /resources/group,
+// indexed by group name.
+func getDscpGroupNodes(root xutils.XpathNode) map[string]xutils.XpathNode {
+	groupNodes := common.GetDescendantNodesFromSingleNode(
+		root, []xutils.XFilter{
+			resourcesFilter,
+			groupFilter,
+			resourcesDscpGroupFilter,
+		})
+
+	groups := make(map[string]xutils.XpathNode, len(groupNodes))
+	for _, groupNode := range groupNodes {
+		groups[groupNode.XValue()] = groupNode
+	}
+	return groups
+}
+
+// getDscpGroupValues - returns the DSCP values covered by a dscp-group.
+// Returns false if any dscp value cannot be parsed.
+func getDscpGroupValues(groupNode xutils.XpathNode) ([]int, bool) {
+	var allValues []int
+	for _, dscpNode := range groupNode.XChildren(
+		dscpFilter, xutils.Unsorted) {
+		values, ok := common.ParseDscpValues(dscpNode.XValue())
+		if !ok {
+			return nil, false
+		}
+		allValues = append(allValues, values...)
+	}
+	return allValues, true
+}
+
+// verifyIngressMapCoverage
+//
+// Applied to an ingress-map.  An ingress-map classifies either by PCP or by
+// DSCP group, not both, and must classify every value exactly once, ie:
+//
+//  - for PCP, every PCP value (0-7) must have a pcp entry; or
+//  - for DSCP, the dscp-groups used must between them contain every DSCP
+//    value (0-63), with no value in more than one group.
+//
+//  configd:must "verify-ingress-map-coverage(.)"
+//
+func verifyIngressMapCoverage(
+	args []xpath.Datum,
+) (retBool xpath.Datum) {
+
+	// Function has flexibility to be applied to '.' or any other node,
+	// rather than just the current node, but should only be applied to
+	// a single node.  So, if nodeset is empty or has multiple entries,
+	// return false.
+	ns0 := args[0].Nodeset("verify-ingress-map-coverage()")
+	if len(ns0) != 1 {
+		return xpath.NewBoolDatum(false)
+	}
+	mapNode := ns0[0]
+
+	pcpNodes := mapNode.XChildren(pcpFilter, xutils.Unsorted)
+	dscpGroupNodes := mapNode.XChildren(dscpGroupFilter, xutils.Unsorted)
+
+	switch {
+	case len(pcpNodes) != 0 && len(dscpGroupNodes) != 0:
+		return xpath.NewBoolDatum(false)
+	case len(pcpNodes) != 0:
+		var pcpSeen [numPcpValues]bool
+		for _, pcpNode := range pcpNodes {
+			pcp, err := strconv.Atoi(pcpNode.XValue())
+			if err != nil || pcp < 0 || pcp >= numPcpValues || pcpSeen[pcp] {
+				return xpath.NewBoolDatum(false)
+			}
+			pcpSeen[pcp] = true
+		}
+		return xpath.NewBoolDatum(len(pcpNodes) == numPcpValues)
+	case len(dscpGroupNodes) != 0:
+		// Reuse the map coverage check, which has the same semantics.
+		return verifyDscpGroupCoverageInternal(
+			[]xpath.Datum{xpath.NewNodesetDatum(ns0)},
+			"verify-ingress-map-coverage()", true)
+	}
+
+	// No classification at all.
+	return xpath.NewBoolDatum(false)
+}
+
+// verifyIngressMapDesignations
+//
+// Applied to an ingress-map.  Checks that each designation used by the
+// ingress-map's pcp and dscp-group entries is in range, and that for every
+// shaper defining designations, the designation is defined there and its
+// queue belongs to a valid traffic class in the profiles used by the
+// shaper.  Shapers with no designations configured are not checked.
+//
+//  configd:must "verify-ingress-map-designations(.)"
+//
+func verifyIngressMapDesignations(
+	args []xpath.Datum,
+) (retBool xpath.Datum) {
+
+	// Function has flexibility to be applied to '.' or any other node,
+	// rather than just the current node, but should only be applied to
+	// a single node.  So, if nodeset is empty or has multiple entries,
+	// return false.
+	ns0 := args[0].Nodeset("verify-ingress-map-designations()")
+	if len(ns0) != 1 {
+		return xpath.NewBoolDatum(false)
+	}
+	mapNode := ns0[0]
+
+	var entryNodes []xutils.XpathNode
+	entryNodes = append(entryNodes,
+		mapNode.XChildren(pcpFilter, xutils.Unsorted)...)
+	entryNodes = append(entryNodes,
+		mapNode.XChildren(dscpGroupFilter, xutils.Unsorted)...)
+
+	designations := make(map[string]bool, numDesignations)
+	for _, entryNode := range entryNodes {
+		designation, ok := common.GetSingleChildValue(
+			entryNode, designationFilter)
+		if !ok || !isUintInRange(designation, numDesignations-1) {
+			return xpath.NewBoolDatum(false)
+		}
+		designations[designation] = true
+	}
+
+	shaperNodes := common.GetDescendantNodesFromSingleNode(
+		mapNode.XRoot(), []xutils.XFilter{
+			policyFilter,
+			qosFilter,
+			nameFilter,
+			shaperFilter,
+		})
+	for _, shaperNode := range shaperNodes {
+		for designation := range designations {
+			if !isDesignationValidForShaper(shaperNode, designation) {
+				return xpath.NewBoolDatum(false)
+			}
+		}
+	}
+
+	return xpath.NewBoolDatum(true)
+}
+
+// isDesignationValidForShaper - returns true if the shaper has no
+// designations configured, or if the given designation is configured with
+// a queue that has a valid traffic class in every profile used by the
+// shaper that defines that queue, and at least one profile does so.
+func isDesignationValidForShaper(
+	shaperNode xutils.XpathNode,
+	designation string,
+) bool {
+	designationNodes := shaperNode.XChildren(
+		designationFilter, xutils.Unsorted)
+	if len(designationNodes) == 0 {
+		return true
+	}
+
+	queue := ""
+	for _, designationNode := range designationNodes {
+		if designationNode.XValue() != designation {
+			continue
+		}
+		var ok bool
+		if queue, ok = common.GetSingleChildValue(
+			designationNode, queueFilter); !ok {
+			return false
+		}
+		break
+	}
+	if queue == "" {
+		return false
+	}
+
+	queueFound := false
+	for _, profileNode := range getProfilesUsedByShaper(shaperNode) {
+		for _, queueNode := range profileNode.XChildren(
+			queueFilter, xutils.Unsorted) {
+			if queueNode.XValue() != queue {
+				continue
+			}
+			trafficClass, ok := common.GetSingleChildValue(
+				queueNode, trafficClassFilter)
+			if !ok || !isUintInRange(trafficClass, numTrafficClasses-1) ||
+				!isTrafficClassDefined(shaperNode, trafficClass) {
+				return false
+			}
+			queueFound = true
+		}
+	}
+	return queueFound
+}
+
+// getProfilesUsedByShaper - returns the shaper's local profiles, and any
+// global profiles it references, either as its default profile or from a
+// class.
+func getProfilesUsedByShaper(
+	shaperNode xutils.XpathNode,
+) []xutils.XpathNode {
+	profileNodes := shaperNode.XChildren(profileFilter, xutils.Unsorted)
+
+	referenced := getProfileReferences(shaperNode)
+	globalProfileNodes := common.GetDescendantNodesFromSingleNode(
+		shaperNode.XRoot(), []xutils.XFilter{
+			policyFilter,
+			qosFilter,
+			profileFilter,
+		})
+	for _, profileNode := range globalProfileNodes {
+		if referenced[profileNode.XValue()] {
+			profileNodes = append(profileNodes, profileNode)
+		}
+	}
+	return profileNodes
+}
+
+// getProfileReferences - return the names of the profiles referenced by a
+// shaper, either as its default profile or from a class.
+func getProfileReferences(shaperNode xutils.XpathNode) map[string]bool {
+	referenced := make(map[string]bool)
+	if defProfile, ok := common.GetSingleChildValue(
+		shaperNode, defaultFilter); ok {
+		referenced[defProfile] = true
+	}
+	for _, classNode := range shaperNode.XChildren(
+		classFilter, xutils.Unsorted) {
+		if profile, ok := common.GetSingleChildValue(
+			classNode, profileFilter); ok {
+			referenced[profile] = true
+		}
+	}
+	return referenced
+}
+
+// verifyIngressMapSystemDefaultUnique
+//
+// Only one ingress-map may be marked as the system default.
+//
+//  must "count(/policy/ingress-map[system-default]) <= 1"
+//
+func verifyIngressMapSystemDefaultUnique(
+	args []xpath.Datum,
+) (retBool xpath.Datum) {
+
+	// Function has flexibility to be applied to '.' or any other node,
+	// rather than just the current node, but should only be applied to
+	// a single node.  So, if nodeset is empty or has multiple entries,
+	// return false.
+	ns0 := args[0].Nodeset("verify-ingress-map-system-default-unique()")
+	if len(ns0) != 1 {
+		return xpath.NewBoolDatum(false)
+	}
+
+	mapNodes := common.GetDescendantNodesFromSingleNode(
+		ns0[0].XRoot(), []xutils.XFilter{
+			policyFilter,
+			ingressMapFilter,
+		})
+	systemDefaultCount := 0
+	for _, mapNode := range mapNodes {
+		if len(mapNode.XChildren(systemDefaultFilter, xutils.Unsorted)) != 0 {
+			systemDefaultCount++
+		}
+	}
+
+	return xpath.NewBoolDatum(systemDefaultCount <= 1)
+}
+
+// verifyShaperRateHierarchy
+//
+// Applied to a shaper (subport).  Checks that rates do not exceed their
+// parent's rate down the hierarchy:
+//
+//   interface speed -> shaper -> shaper traffic-class
+//                              -> profile (pipe) -> profile traffic-class
+//
+// where the profiles are the shaper's local profiles and any global
+// profiles it references.  Bandwidths may be absolute (eg "100Mbit") or a
+// percentage of the parent's rate.  The shaper's parent rate is the lowest
+// fixed speed of the interfaces (or parents of VIFs) the policy is attached
+// to; if there is none, percentages at shaper level can't be resolved, and
+// only the relative checks below that level are made.
+//
+// Burst sizes (bytes) must likewise not exceed the parent's burst size,
+// where both are set.
+//
+//  configd:must "verify-shaper-rate-hierarchy(.)"
+//
+func verifyShaperRateHierarchy(
+	args []xpath.Datum,
+) (retBool xpath.Datum) {
+
+	// Function has flexibility to be applied to '.' or any other node,
+	// rather than just the current node, but should only be applied to
+	// a single node.  So, if nodeset is empty or has multiple entries,
+	// return false.
+	ns0 := args[0].Nodeset("verify-shaper-rate-hierarchy()")
+	if len(ns0) != 1 {
+		return xpath.NewBoolDatum(false)
+	}
+	shaperNode := ns0[0]
+
+	shaperRate, ok := resolveRate(
+		shaperNode, getShaperInterfaceRate(shaperNode))
+	if !ok {
+		return xpath.NewBoolDatum(false)
+	}
+	shaperBurst, ok := resolveBurst(shaperNode, 0)
+	if !ok {
+		return xpath.NewBoolDatum(false)
+	}
+
+	if !verifyTrafficClassRates(shaperNode, shaperRate, shaperBurst) {
+		return xpath.NewBoolDatum(false)
+	}
+
+	for _, profileNode := range getProfilesUsedByShaper(shaperNode) {
+		profileRate, ok := resolveRate(profileNode, shaperRate)
+		if !ok {
+			return xpath.NewBoolDatum(false)
+		}
+		profileBurst, ok := resolveBurst(profileNode, shaperBurst)
+		if !ok {
+			return xpath.NewBoolDatum(false)
+		}
+		if !verifyTrafficClassRates(
+			profileNode, profileRate, profileBurst) {
+			return xpath.NewBoolDatum(false)
+		}
+	}
+
+	return xpath.NewBoolDatum(true)
+}
+
+// rate - a resolved rate in bit/s.  A percentage of an unknown rate is
+// itself unknown.
+type rate struct {
+	bps   uint64
+	known bool
+}
+
+// resolveRate - returns the rate configured by node's bandwidth, or the
+// parent's rate if there is no bandwidth.  Returns false if the bandwidth
+// is invalid or exceeds the parent's rate.
+func resolveRate(node xutils.XpathNode, parentRate rate) (rate, bool) {
+	bandwidthVal, ok := common.GetSingleChildValue(node, bandwidthFilter)
+	if !ok {
+		return parentRate, true
+	}
+	bandwidth, ok := common.ParseBandwidth(bandwidthVal)
+	if !ok {
+		return rate{}, false
+	}
+
+	if bandwidth.IsPercent() {
+		// Percentages are capped at 100, so can't exceed the parent.
+		if !parentRate.known {
+			return rate{}, true
+		}
+		return rate{bps: bandwidth.Resolve(parentRate.bps), known: true}, true
+	}
+
+	bps := bandwidth.Resolve(0)
+	if parentRate.known && bps > parentRate.bps {
+		return rate{}, false
+	}
+	return rate{bps: bps, known: true}, true
+}
+
+// resolveBurst - returns node's burst size, or the parent's if there is no
+// burst size.  Zero means no burst size is set.  Returns false if the burst
+// size is invalid or exceeds the parent's burst size.
+func resolveBurst(node xutils.XpathNode, parentBurst uint64) (uint64, bool) {
+	burstVal, ok := common.GetSingleChildValue(node, burstFilter)
+	if !ok {
+		return parentBurst, true
+	}
+	burst, err := strconv.ParseUint(burstVal, 10, 64)
+	if err != nil || burst == 0 {
+		return 0, false
+	}
+	if parentBurst != 0 && burst > parentBurst {
+		return 0, false
+	}
+	return burst, true
+}
+
+// verifyTrafficClassRates - check the rate and burst size of each of the
+// traffic-class entries under node against node's own.
+func verifyTrafficClassRates(
+	node xutils.XpathNode,
+	parentRate rate,
+	parentBurst uint64,
+) bool {
+	for _, tcNode := range node.XChildren(trafficClassFilter, xutils.Unsorted) {
+		if _, ok := resolveRate(tcNode, parentRate); !ok {
+			return false
+		}
+		if _, ok := resolveBurst(tcNode, parentBurst); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// getShaperInterfaceRate - return the lowest fixed speed of any interface
+// that the shaper's policy is attached to, either directly or on one of its
+// VIFs.  Interfaces with 'auto' or no speed are ignored.
+func getShaperInterfaceRate(shaperNode xutils.XpathNode) rate {
+	policyName := shaperNode.XParent().XValue()
+
+	intfNodes := common.GetDescendantNodesFromSingleNode(
+		shaperNode.XRoot(), []xutils.XFilter{
+			interfacesFilter,
+			allChildrenFilter,
+		})
+
+	intfRate := rate{}
+	for _, intfNode := range intfNodes {
+		if !isPolicyAttached(intfNode, policyName) {
+			continue
+		}
+		speedVal, ok := common.GetSingleChildValue(intfNode, speedFilter)
+		if !ok {
+			continue
+		}
+		speed, ok := common.ParseSpeed(speedVal)
+		if !ok || speed.IsAuto() {
+			continue
+		}
+		bps := speed.Mbps() * 1e6
+		if !intfRate.known || bps < intfRate.bps {
+			intfRate = rate{bps: bps, known: true}
+		}
+	}
+	return intfRate
+}
+
+// isPolicyAttached - true if interface or any of its VIFs has the named
+// QoS policy attached.
+func isPolicyAttached(intfNode xutils.XpathNode, policyName string) bool {
+	attachNodes := []xutils.XpathNode{intfNode}
+	attachNodes = append(attachNodes,
+		intfNode.XChildren(vifFilter, xutils.Unsorted)...)
+
+	for _, attachNode := range attachNodes {
+		qosNodes := common.GetDescendantNodesFromSingleNode(
+			attachNode, []xutils.XFilter{intfPolicyFilter, qosFilter})
+		for _, qosNode := range qosNodes {
+			if qosNode.XValue() == policyName {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// verifyWredThresholds
+//
+// Applied to a (local or global) profile queue.  Each entry in the queue's
+// wred-map (per colour, or per dscp-group) must have:
+//
+//  - min-threshold < max-threshold;
+//  - max-threshold no greater than the queue limit, where known; and
+//  - a mark-probability, if set, that is a positive integer.
+//
+// In addition, a dscp-group wred-map entry must be for a dscp-group that
+// the profile's map uses, otherwise it will never apply.
+//
+// The queue limit is taken from the queue itself if set, otherwise from the
+// profile's entry for the queue's traffic class, otherwise from the
+// shaper's entry for the traffic class (local profiles only).
+//
+//  configd:must "verify-wred-thresholds(.)"
+//
+func verifyWredThresholds(
+	args []xpath.Datum,
+) (retBool xpath.Datum) {
+
+	// Function has flexibility to be applied to '.' or any other node,
+	// rather than just the current node, but should only be applied to
+	// a single node.  So, if nodeset is empty or has multiple entries,
+	// return false.
+	ns0 := args[0].Nodeset("verify-wred-thresholds()")
+	if len(ns0) != 1 {
+		return xpath.NewBoolDatum(false)
+	}
+	queueNode := ns0[0]
+	profileNode := queueNode.XParent()
+
+	queueLimit, ok := getQueueLimit(queueNode)
+	if !ok {
+		return xpath.NewBoolDatum(false)
+	}
+
+	mapGroups := make(map[string]bool)
+	for _, mapGroupNode := range common.GetDescendantNodesFromSingleNode(
+		profileNode, []xutils.XFilter{mapFilter, dscpGroupFilter}) {
+		mapGroups[mapGroupNode.XValue()] = true
+	}
+
+	colourNodes := common.GetDescendantNodesFromSingleNode(
+		queueNode, []xutils.XFilter{wredMapFilter, dropPrecedenceFilter})
+	for _, wredEntryNode := range colourNodes {
+		if !verifyWredEntry(wredEntryNode, queueLimit) {
+			return xpath.NewBoolDatum(false)
+		}
+	}
+
+	groupNodes := common.GetDescendantNodesFromSingleNode(
+		queueNode, []xutils.XFilter{wredMapFilter, dscpGroupFilter})
+	for _, wredEntryNode := range groupNodes {
+		if !mapGroups[wredEntryNode.XValue()] ||
+			!verifyWredEntry(wredEntryNode, queueLimit) {
+			return xpath.NewBoolDatum(false)
+		}
+	}
+
+	return xpath.NewBoolDatum(true)
+}
+
+// verifyWredEntry - check thresholds and mark probability of a single
+// wred-map entry.  A queueLimit of zero means the limit is not known.
+func verifyWredEntry(wredEntryNode xutils.XpathNode, queueLimit uint64) bool {
+	minVal, ok := common.GetSingleChildValue(
+		wredEntryNode, minThresholdFilter)
+	if !ok {
+		return false
+	}
+	maxVal, ok := common.GetSingleChildValue(
+		wredEntryNode, maxThresholdFilter)
+	if !ok {
+		return false
+	}
+	minThreshold, err := strconv.ParseUint(minVal, 10, 64)
+	if err != nil {
+		return false
+	}
+	maxThreshold, err := strconv.ParseUint(maxVal, 10, 64)
+	if err != nil {
+		return false
+	}
+	if minThreshold >= maxThreshold {
+		return false
+	}
+	if queueLimit != 0 && maxThreshold > queueLimit {
+		return false
+	}
+
+	if markProb, ok := common.GetSingleChildValue(
+		wredEntryNode, markProbabilityFilter); ok {
+		if prob, err := strconv.ParseUint(markProb, 10, 64); err != nil ||
+			prob == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// getQueueLimit - return the queue limit applying to the queue, or zero if
+// none is configured.  Returns false if the limit is invalid.
+func getQueueLimit(queueNode xutils.XpathNode) (uint64, bool) {
+	profileNode := queueNode.XParent()
+
+	// Queue, then profile, then shaper (local profiles only).
+	limitNodes := []xutils.XpathNode{queueNode}
+	if trafficClass, ok := common.GetSingleChildValue(
+		queueNode, trafficClassFilter); ok {
+		limitNodes = append(limitNodes,
+			getTrafficClassNode(profileNode, trafficClass))
+		shaperNode := profileNode.XParent()
+		if shaperNode.XName() == "shaper" {
+			limitNodes = append(limitNodes,
+				getTrafficClassNode(shaperNode, trafficClass))
+		}
+	}
+
+	for _, limitNode := range limitNodes {
+		if limitNode == nil {
+			continue
+		}
+		limitVal, ok := common.GetSingleChildValue(
+			limitNode, queueLimitFilter)
+		if !ok {
+			continue
+		}
+		limit, err := strconv.ParseUint(limitVal, 10, 64)
+		if err != nil || limit == 0 {
+			return 0, false
+		}
+		return limit, true
+	}
+	return 0, true
+}
+
+// getTrafficClassNode - return node's traffic-class entry with the given
+// ID, or nil if there isn't one.
+func getTrafficClassNode(
+	node xutils.XpathNode,
+	trafficClass string,
+) xutils.XpathNode {
+	for _, tcNode := range node.XChildren(
+		trafficClassFilter, xutils.Unsorted) {
+		if tcNode.XValue() == trafficClass {
+			return tcNode
+		}
+	}
+	return nil
+}
+
+// isQosPolicyLeafref - implementation of is-qos-policy-leafref(<nodeset>)
+//
+// Checks the node's value is the name of a QoS policy.  Replaces:
+//
+//  must "/policy/qos/name[name = current()]"
+//
+func isQosPolicyLeafref(
+	args []xpath.Datum,
+) (retBool xpath.Datum) {
+
+	// Function has flexibility to be applied to '.' or any other node,
+	// rather than just the current node, but should only be applied to
+	// a single node.  So, if nodeset is empty or has multiple entries,
+	// return false.
+	ns0 := args[0].Nodeset("is-qos-policy-leafref()")
+	if len(ns0) != 1 {
+		return xpath.NewBoolDatum(false)
+	}
+	srcNode := ns0[0]
+
+	_, ok := getQosPolicyNode(srcNode.XRoot(), srcNode.XValue())
+	return xpath.NewBoolDatum(ok)
+}
+
+// verifyVifQosAttachment
+//
+// Applied to a QoS attachment (eg policy/qos, policy/ingress-map or
+// policy/egress-map) on an interface.  The interface and, for a VIF, its
+// parent port are resolved by name as for is-interface-leafref().  If the
+// interface is a VIF, its parent port must have a QoS policy attached, and
+// that policy must have a shaper, as VIF traffic is shaped by the parent
+// port.  Attachments that are not on a VIF are always permitted.
+//
+//  configd:must "verify-vif-qos-attachment(.)"
+//
+func verifyVifQosAttachment(
+	args []xpath.Datum,
+) (retBool xpath.Datum) {
+
+	// Function has flexibility to be applied to '.' or any other node,
+	// rather than just the current node, but should only be applied to
+	// a single node.  So, if nodeset is empty or has multiple entries,
+	// return false.
+	ns0 := args[0].Nodeset("verify-vif-qos-attachment()")
+	if len(ns0) != 1 {
+		return xpath.NewBoolDatum(false)
+	}
+	srcNode := ns0[0]
+
+	// Resolve the interface or VIF the attachment is on (the parent of its
+	// policy container) by name, and from that the parent port.
+	intfNode := srcNode.XParent().XParent()
+	if intfNode == nil {
+		return xpath.NewBoolDatum(false)
+	}
+	intfName := common.GetInterfaceName(intfNode)
+	if _, ok := common.GetInterfaceNode(
+		srcNode.XRoot(), intfName, nil); !ok {
+		return xpath.NewBoolDatum(false)
+	}
+	parentName, isVif, _ := common.ParseInterfaceName(intfName)
+	if !isVif {
+		return xpath.NewBoolDatum(true)
+	}
+	parentIntfNode, ok := common.GetInterfaceNode(
+		srcNode.XRoot(), parentName, nil)
+	if !ok {
+		return xpath.NewBoolDatum(false)
+	}
+
+	policyNames := common.GetDescendantNodesFromSingleNode(
+		parentIntfNode, []xutils.XFilter{intfPolicyFilter, qosFilter})
+	if len(policyNames) != 1 {
+		return xpath.NewBoolDatum(false)
+	}
+	policyNode, ok := getQosPolicyNode(
+		srcNode.XRoot(), policyNames[0].XValue())
+	if !ok {
+		return xpath.NewBoolDatum(false)
+	}
+	return xpath.NewBoolDatum(
+		len(policyNode.XChildren(shaperFilter, xutils.Unsorted)) != 0)
+}
+
+// getQosPolicyNode - return the /policy/qos/name entry for policyName.
+func getQosPolicyNode(
+	root xutils.XpathNode,
+	policyName string,
+) (xutils.XpathNode, bool) {
+	policyNodes := common.GetDescendantNodesFromSingleNode(
+		root, []xutils.XFilter{
+			policyFilter,
+			qosFilter,
+			nameFilter,
+		})
+	for _, policyNode := range policyNodes {
+		if policyNode.XValue() == policyName {
+			return policyNode, true
+		}
+	}
+	return nil, false
+}
+
+// The qos-unused-* functions return a space-separated, sorted list of the
+// QoS definitions that nothing references, or an empty string if all are
+// used.  The node provided is only used to find the root of the tree, so
+// '.' can be used from anywhere.  Custom functions are only given their
+// arguments, not the context node, so a zero-argument form can't reach the
+// tree.
+
+// qosUnusedProfiles - profiles no shaper uses, local ones as policy/profile
+func qosUnusedProfiles(
+	args []xpath.Datum,
+) (retString xpath.Datum) {
+
+	ns0 := args[0].Nodeset("qos-unused-profiles()")
+	if len(ns0) != 1 {
+		return xpath.NewLiteralDatum("")
+	}
+	root := ns0[0].XRoot()
+
+	var unused []string
+	usedGlobalProfiles := make(map[string]bool)
+	shaperNodes := common.GetDescendantNodesFromSingleNode(
+		root, []xutils.XFilter{
+			policyFilter,
+			qosFilter,
+			nameFilter,
+			shaperFilter,
+		})
+	for _, shaperNode := range shaperNodes {
+		referenced := getProfileReferences(shaperNode)
+		localProfiles := make(map[string]bool)
+		for _, profileNode := range shaperNode.XChildren(
+			profileFilter, xutils.Unsorted) {
+			localProfiles[profileNode.XValue()] = true
+			if !referenced[profileNode.XValue()] {
+				unused = append(unused, shaperNode.XParent().XValue()+
+					"/"+profileNode.XValue())
+			}
+		}
+
+		// A reference resolves to the shaper's own local profile, if it
+		// has one of that name, so only uses the global profile otherwise.
+		for profile := range referenced {
+			if !localProfiles[profile] {
+				usedGlobalProfiles[profile] = true
+			}
+		}
+	}
+
+	globalProfileNodes := common.GetDescendantNodesFromSingleNode(
+		root, []xutils.XFilter{
+			policyFilter,
+			qosFilter,
+			profileFilter,
+		})
+	for _, profileNode := range globalProfileNodes {
+		if !usedGlobalProfiles[profileNode.XValue()] {
+			unused = append(unused, profileNode.XValue())
+		}
+	}
+
+	return xpath.NewLiteralDatum(joinNames(unused))
+}
+
+// qosUnusedDscpGroups - dscp-groups not referenced anywhere under /policy
+func qosUnusedDscpGroups(
+	args []xpath.Datum,
+) (retString xpath.Datum) {
+
+	ns0 := args[0].Nodeset("qos-unused-dscp-groups()")
+	if len(ns0) != 1 {
+		return xpath.NewLiteralDatum("")
+	}
+	root := ns0[0].XRoot()
+
+	used := make(map[string]bool)
+	for _, policyNode := range root.XChildren(
+		policyFilter, xutils.Unsorted) {
+		for _, refNode := range common.GetAllDescendantNodesWithName(
+			policyNode, "dscp-group") {
+			used[refNode.XValue()] = true
+		}
+	}
+
+	var unused []string
+	groupNodes := common.GetDescendantNodesFromSingleNode(
+		root, []xutils.XFilter{
+			resourcesFilter,
+			groupFilter,
+			resourcesDscpGroupFilter,
+		})
+	for _, groupNode := range groupNodes {
+		if !used[groupNode.XValue()] {
+			unused = append(unused, groupNode.XValue())
+		}
+	}
+
+	return xpath.NewLiteralDatum(joinNames(unused))
+}
+
+// joinNames - sort names and return them as a space-separated string.
+func joinNames(names []string) string {
+	sort.Strings(names)
+	return strings.Join(names, " ")
+}
+
+// qosProfileSignature - implementation of qos-profile-signature(<nodeset>)
+//
+// Returns a canonical string describing a (local or global) profile's
+// queues and map entries, so that profiles can be compared directly, and
+// the profiles that differ identified when verify-queue-id-and-traffic-class
+// or verify-dscp-group-to-queue-mappings fail.  For example:
+//
+//   queue(0,0,-) queue(1,1,5) dscp-group(high,1) dscp-group(low,0)
+//
+// Queues are given as queue(id,traffic-class,weight), sorted by id, and map
+// entries as dscp-group(group-name,to) then pcp(id,to), each sorted by
+// key.  Missing values are shown as '-'.
+func qosProfileSignature(
+	args []xpath.Datum,
+) (retString xpath.Datum) {
+
+	// Function has flexibility to be applied to '.' or any other node,
+	// rather than just the current node, but should only be applied to
+	// a single node.  So, if nodeset is empty or has multiple entries,
+	// return an empty string.
+	ns0 := args[0].Nodeset("qos-profile-signature()")
+	if len(ns0) != 1 {
+		return xpath.NewLiteralDatum("")
+	}
+	profileNode := ns0[0]
+
+	queues := getSignatureEntries(profileNode.XChildren(
+		queueFilter, xutils.Unsorted),
+		"queue", idFilter, trafficClassFilter, weightFilter)
+
+	mapNodes := profileNode.XChildren(mapFilter, xutils.Unsorted)
+	dscpGroups := getSignatureEntries(common.GetDescendantNodes(
+		mapNodes, []xutils.XFilter{dscpGroupFilter}),
+		"dscp-group", groupNameFilter, toFilter)
+	pcps := getSignatureEntries(common.GetDescendantNodes(
+		mapNodes, []xutils.XFilter{pcpFilter}),
+		"pcp", idFilter, toFilter)
+
+	return xpath.NewLiteralDatum(strings.Join(
+		append(append(queues, dscpGroups...), pcps...), " "))
+}
+
+// getSignatureEntries - return name(value,value,...) for each node, using
+// the values of the leaves given by filters, sorted by the first of these.
+// Numeric keys are sorted numerically.
+func getSignatureEntries(
+	nodes []xutils.XpathNode,
+	name string,
+	filters ...xutils.XFilter,
+) []string {
+	type entry struct {
+		key       string
+		signature string
+	}
+	entries := make([]entry, 0, len(nodes))
+	for _, node := range nodes {
+		values := make([]string, 0, len(filters))
+		for _, filter := range filters {
+			value, ok := common.GetSingleChildValue(node, filter)
+			if !ok {
+				value = "-"
+			}
+			values = append(values, value)
+		}
+		entries = append(entries, entry{
+			key:       values[0],
+			signature: name + "(" + strings.Join(values, ",") + ")",
+		})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		iNum, iErr := strconv.ParseUint(entries[i].key, 10, 64)
+		jNum, jErr := strconv.ParseUint(entries[j].key, 10, 64)
+		if iErr == nil && jErr == nil {
+			return iNum < jNum
+		}
+		return entries[i].key < entries[j].key
+	})
+
+	signatures := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		signatures = append(signatures, entry.signature)
+	}
+	return signatures
+}
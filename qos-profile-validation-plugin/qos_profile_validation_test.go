@@ -5,8 +5,11 @@
 package main
 
 import (
+	"strconv"
+	"strings"
 	"testing"
 
+	"github.com/danos/xpath-plugins/common"
 	"github.com/danos/yang/xpath"
 	"github.com/danos/yang/xpath/xpathtest"
 	"github.com/danos/yang/xpath/xutils"
@@ -179,6 +182,32 @@ func TestQueueMatch(t *testing.T) {
 			startPath: "/policy/qos/name/shaper/profile/queue",
 			expResult: true,
 		},
+		{
+			// Used to pass, as a missing traffic-class counted as a match.
+			name: "Other profile queue missing traffic-class - FAIL",
+			config: []xutils.PathType{
+				{"policy", "qos", "profile/name+prof1", "queue/id+1",
+					"traffic-class+tc1"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "queue/id+1", "weight+5"},
+			},
+			startPath: "/policy/qos/profile/queue",
+			expResult: false,
+		},
+		{
+			// Used to pass, as a duplicate traffic-class counted as a match.
+			name: "Other profile queue with multiple traffic-class - FAIL",
+			config: []xutils.PathType{
+				{"policy", "qos", "profile/name+prof1", "queue/id+1",
+					"traffic-class+tc1"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "queue/id+1", "traffic-class@tc1"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "queue/id+1", "traffic-class@tc2"},
+			},
+			startPath: "/policy/qos/profile/queue",
+			expResult: false,
+		},
 	}
 
 	for _, test := range tests {
@@ -419,6 +448,19 @@ func TestMapMatch(t *testing.T) {
 			startPath: "/policy/qos/profile/map/dscp-group",
 			expResult: false,
 		},
+		{
+			// Used to pass, as a missing 'to' counted as a match.
+			name: "Other profile map entry missing 'to' - FAIL",
+			config: []xutils.PathType{
+				{"policy", "qos", "profile/name+prof1", "map",
+					"dscp-group/group-name+high", "to+4"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "map",
+					"dscp-group/group-name+high"},
+			},
+			startPath: "/policy/qos/profile/map/dscp-group",
+			expResult: false,
+		},
 	}
 
 	for _, test := range tests {
@@ -439,3 +481,1762 @@ func TestMapMatch(t *testing.T) {
 		})
 	}
 }
+
+func TestQueueReferences(t *testing.T) {
+
+	tests := []qosProfileTestSpec{
+		{
+			name: "Local profile, no traffic-classes defined - FAIL",
+			config: []xutils.PathType{
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "queue/id+1", "traffic-class+1"},
+			},
+			startPath: "/policy/qos/name/shaper/profile/queue",
+			expResult: false,
+		},
+		{
+			name: "Local profile, weight with pipe traffic-class - PASS",
+			config: []xutils.PathType{
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "queue/id+1", "traffic-class+1"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "queue/id+1", "weight+50"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "traffic-class/id+1"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"traffic-class/id+1"},
+			},
+			startPath: "/policy/qos/name/shaper/profile/queue",
+			expResult: true,
+		},
+		{
+			name: "Local profile, weight without pipe traffic-class - FAIL",
+			config: []xutils.PathType{
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "queue/id+1", "traffic-class+1"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "queue/id+1", "weight+50"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"traffic-class/id+1"},
+			},
+			startPath: "/policy/qos/name/shaper/profile/queue",
+			expResult: false,
+		},
+		{
+			name: "Local profile, zero weight - FAIL",
+			config: []xutils.PathType{
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "queue/id+1", "traffic-class+1"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "queue/id+1", "weight+0"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "traffic-class/id+1"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"traffic-class/id+1"},
+			},
+			startPath: "/policy/qos/name/shaper/profile/queue",
+			expResult: false,
+		},
+		{
+			name: "Local profile, weight out of range - FAIL",
+			config: []xutils.PathType{
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "queue/id+1", "traffic-class+1"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "queue/id+1", "weight+101"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "traffic-class/id+1"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"traffic-class/id+1"},
+			},
+			startPath: "/policy/qos/name/shaper/profile/queue",
+			expResult: false,
+		},
+		{
+			name: "Local profile, queue id out of range - FAIL",
+			config: []xutils.PathType{
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "queue/id+32", "traffic-class+1"},
+			},
+			startPath: "/policy/qos/name/shaper/profile/queue",
+			expResult: false,
+		},
+		{
+			name: "Local profile, traffic-class out of range - FAIL",
+			config: []xutils.PathType{
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "queue/id+1", "traffic-class+4"},
+			},
+			startPath: "/policy/qos/name/shaper/profile/queue",
+			expResult: false,
+		},
+		{
+			name: "Local profile, traffic-class not a number - FAIL",
+			config: []xutils.PathType{
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "queue/id+1", TRAFFIC_CLASS_1},
+			},
+			startPath: "/policy/qos/name/shaper/profile/queue",
+			expResult: false,
+		},
+		{
+			name: "Local profile, missing traffic-class - FAIL",
+			config: []xutils.PathType{
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "queue/id+1", "weight+5"},
+			},
+			startPath: "/policy/qos/name/shaper/profile/queue",
+			expResult: false,
+		},
+		{
+			name: "Local profile, traffic-class defined by shaper - PASS",
+			config: []xutils.PathType{
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "queue/id+1", "traffic-class+1"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"traffic-class/id+0"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"traffic-class/id+1"},
+			},
+			startPath: "/policy/qos/name/shaper/profile/queue",
+			expResult: true,
+		},
+		{
+			name: "Local profile, traffic-class not defined by shaper - FAIL",
+			config: []xutils.PathType{
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "queue/id+1", "traffic-class+2"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"traffic-class/id+0"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"traffic-class/id+1"},
+			},
+			startPath: "/policy/qos/name/shaper/profile/queue",
+			expResult: false,
+		},
+		{
+			name: "Local profile, traffic-class not defined by pipe - FAIL",
+			config: []xutils.PathType{
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "queue/id+1", "traffic-class+2"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "traffic-class/id+0"},
+			},
+			startPath: "/policy/qos/name/shaper/profile/queue",
+			expResult: false,
+		},
+		{
+			name: "Local profile, too many queues for traffic-class - FAIL",
+			config: []xutils.PathType{
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "queue/id+0", "traffic-class+1"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "queue/id+1", "traffic-class+1"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "queue/id+2", "traffic-class+1"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "queue/id+3", "traffic-class+1"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "queue/id+4", "traffic-class+1"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "queue/id+5", "traffic-class+1"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "queue/id+6", "traffic-class+1"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "queue/id+7", "traffic-class+1"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "queue/id+8", "traffic-class+1"},
+			},
+			startPath: "/policy/qos/name/shaper/profile/queue",
+			expResult: false,
+		},
+		{
+			name: "Global profile, unreferenced shaper ignored - PASS",
+			config: []xutils.PathType{
+				{"policy", "qos", "profile/name+prof1", "queue/id+1",
+					"traffic-class+2"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"traffic-class/id+0"},
+			},
+			startPath: "/policy/qos/profile/queue",
+			expResult: true,
+		},
+		{
+			name: "Global profile, default of shaper lacking tc - FAIL",
+			config: []xutils.PathType{
+				{"policy", "qos", "profile/name+prof1", "queue/id+1",
+					"traffic-class+2"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"default+prof1"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"traffic-class/id+0"},
+			},
+			startPath: "/policy/qos/profile/queue",
+			expResult: false,
+		},
+		{
+			name: "Global profile, class of shaper lacking tc - FAIL",
+			config: []xutils.PathType{
+				{"policy", "qos", "profile/name+prof1", "queue/id+1",
+					"traffic-class+2"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"class/id+1", "profile+prof1"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"traffic-class/id+0"},
+			},
+			startPath: "/policy/qos/profile/queue",
+			expResult: false,
+		},
+		{
+			name: "Global profile, class of shaper with tc - PASS",
+			config: []xutils.PathType{
+				{"policy", "qos", "profile/name+prof1", "queue/id+1",
+					"traffic-class+2"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"class/id+1", "profile+prof1"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"traffic-class/id+2"},
+			},
+			startPath: "/policy/qos/profile/queue",
+			expResult: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testTree := xpathtest.CreateTree(t, test.config)
+
+			testNode := testTree.FindFirstNode(
+				xutils.NewPathType(test.startPath))
+			ns := xpath.NewNodesetDatum([]xutils.XpathNode{testNode})
+
+			actResult :=
+				verifyQueueReferences([]xpath.Datum{ns}).Boolean(
+					"(unused value)")
+			if test.expResult != actResult {
+				t.Fatalf("Unexpected result for %s: exp %t, got %t\n",
+					test.name, test.expResult, actResult)
+			}
+		})
+	}
+}
+
+type dscpGroupCoverageTestSpec struct {
+	name            string
+	config          []xutils.PathType
+	startPath       string
+	expResult       bool
+	expFullCoverage bool
+}
+
+func TestDscpGroupCoverage(t *testing.T) {
+
+	// Groups that between them cover all DSCP values exactly once.
+	fullCoverageGroups := []xutils.PathType{
+		{"resources", "group", "dscp-group/group-name+low", "dscp@0-9"},
+		{"resources", "group", "dscp-group/group-name+med", "dscp@af11"},
+		{"resources", "group", "dscp-group/group-name+med", "dscp@11-45"},
+		{"resources", "group", "dscp-group/group-name+high", "dscp@ef"},
+		{"resources", "group", "dscp-group/group-name+high", "dscp@47-63"},
+	}
+
+	tests := []dscpGroupCoverageTestSpec{
+		{
+			name: "Non-overlapping groups, full coverage - PASS",
+			config: append([]xutils.PathType{
+				{"policy", "qos", "profile/name+prof1", "map",
+					DSCP_GRP_LOW, "to+1"},
+				{"policy", "qos", "profile/name+prof1", "map",
+					DSCP_GRP_MED, "to+2"},
+				{"policy", "qos", "profile/name+prof1", "map",
+					DSCP_GRP_HIGH, "to+3"},
+			}, fullCoverageGroups...),
+			startPath:       "/policy/qos/profile/map",
+			expResult:       true,
+			expFullCoverage: true,
+		},
+		{
+			name: "Non-overlapping groups, partial coverage - PASS",
+			config: append([]xutils.PathType{
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "map", DSCP_GRP_LOW, "to+1"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "map", DSCP_GRP_HIGH, "to+3"},
+			}, fullCoverageGroups...),
+			startPath:       "/policy/qos/name/shaper/profile/map",
+			expResult:       true,
+			expFullCoverage: false,
+		},
+		{
+			name: "Overlapping groups unused in same map - PASS",
+			config: []xutils.PathType{
+				{"policy", "qos", "profile/name+prof1", "map",
+					DSCP_GRP_LOW, "to+1"},
+				{"policy", "qos", "profile/name+prof2", "map",
+					DSCP_GRP_HIGH, "to+3"},
+				{"resources", "group", "dscp-group/group-name+low",
+					"dscp@0-10"},
+				{"resources", "group", "dscp-group/group-name+high",
+					"dscp@af11"},
+			},
+			startPath:       "/policy/qos/profile/map",
+			expResult:       true,
+			expFullCoverage: false,
+		},
+		{
+			name: "Range overlapping name in same map - FAIL",
+			config: []xutils.PathType{
+				{"policy", "qos", "profile/name+prof1", "map",
+					DSCP_GRP_LOW, "to+1"},
+				{"policy", "qos", "profile/name+prof1", "map",
+					DSCP_GRP_HIGH, "to+3"},
+				{"resources", "group", "dscp-group/group-name+low",
+					"dscp@0-10"},
+				{"resources", "group", "dscp-group/group-name+high",
+					"dscp@af11"},
+			},
+			startPath:       "/policy/qos/profile/map",
+			expResult:       false,
+			expFullCoverage: false,
+		},
+		{
+			name: "Map references undefined group - FAIL",
+			config: []xutils.PathType{
+				{"policy", "qos", "profile/name+prof1", "map",
+					DSCP_GRP_LOW, "to+1"},
+				{"resources", "group", "dscp-group/group-name+high",
+					"dscp@af11"},
+			},
+			startPath:       "/policy/qos/profile/map",
+			expResult:       false,
+			expFullCoverage: false,
+		},
+		{
+			name: "Group with invalid value - FAIL",
+			config: []xutils.PathType{
+				{"policy", "qos", "profile/name+prof1", "map",
+					DSCP_GRP_LOW, "to+1"},
+				{"resources", "group", "dscp-group/group-name+low",
+					"dscp@64"},
+			},
+			startPath:       "/policy/qos/profile/map",
+			expResult:       false,
+			expFullCoverage: false,
+		},
+		{
+			name: "Unreferenced group with invalid value - PASS",
+			config: append([]xutils.PathType{
+				{"policy", "qos", "profile/name+prof1", "map",
+					DSCP_GRP_LOW, "to+1"},
+				{"policy", "qos", "profile/name+prof1", "map",
+					DSCP_GRP_MED, "to+2"},
+				{"policy", "qos", "profile/name+prof1", "map",
+					DSCP_GRP_HIGH, "to+3"},
+				{"resources", "group", "dscp-group/group-name+unused",
+					"dscp@64"},
+			}, fullCoverageGroups...),
+			startPath:       "/policy/qos/profile/map",
+			expResult:       true,
+			expFullCoverage: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testTree := xpathtest.CreateTree(t, test.config)
+
+			testNode := testTree.FindFirstNode(
+				xutils.NewPathType(test.startPath))
+			ns := xpath.NewNodesetDatum([]xutils.XpathNode{testNode})
+
+			actResult :=
+				verifyDscpGroupCoverage([]xpath.Datum{ns}).Boolean(
+					"(unused value)")
+			if test.expResult != actResult {
+				t.Fatalf("Unexpected result for %s: exp %t, got %t\n",
+					test.name, test.expResult, actResult)
+			}
+
+			actResult =
+				verifyDscpGroupFullCoverage([]xpath.Datum{ns}).Boolean(
+					"(unused value)")
+			if test.expFullCoverage != actResult {
+				t.Fatalf("Unexpected full coverage result for %s: "+
+					"exp %t, got %t\n",
+					test.name, test.expFullCoverage, actResult)
+			}
+		})
+	}
+}
+
+func TestPcpMapMatch(t *testing.T) {
+
+	tests := []qosProfileTestSpec{
+		{
+			name: "Global profile match 'id' and 'to' - PASS",
+			config: []xutils.PathType{
+				{"policy", "qos", "profile/name+prof1", "map",
+					"pcp/id+5", "to+4"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "map", "pcp/id+5", "to+4"},
+			},
+			startPath: "/policy/qos/profile/map/pcp",
+			expResult: true,
+		},
+		{
+			name: "Global profile, mismatched 'to' - FAIL",
+			config: []xutils.PathType{
+				{"policy", "qos", "profile/name+prof1", "map",
+					"pcp/id+5", TO_4},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "map", "pcp/id+5", TO_3},
+			},
+			startPath: "/policy/qos/profile/map/pcp",
+			expResult: false,
+		},
+		{
+			name: "Local profile, mismatched 'id' - FAIL",
+			config: []xutils.PathType{
+				{"policy", "qos", "profile/name+prof1", "map",
+					"pcp/id+6", "to+4"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "map", "pcp/id+5", "to+4"},
+			},
+			startPath: "/policy/qos/name/shaper/profile/map/pcp",
+			expResult: false,
+		},
+		{
+			name: "Local profiles with multiple PCP values - PASS",
+			config: []xutils.PathType{
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "map", "pcp/id+5", "to+4"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "map", "pcp/id+1", "to+0"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profB", "map", "pcp/id+5", "to+4"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profB", "map", "pcp/id+1", "to+0"},
+			},
+			startPath: "/policy/qos/name/shaper/profile/map/pcp",
+			expResult: true,
+		},
+		{
+			name: "Other profile classifies by dscp-group - FAIL",
+			config: []xutils.PathType{
+				{"policy", "qos", "profile/name+prof1", "map",
+					"pcp/id+5", "to+4"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "map", DSCP_GRP_HIGH, "to+4"},
+			},
+			startPath: "/policy/qos/profile/map/pcp",
+			expResult: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testTree := xpathtest.CreateTree(t, test.config)
+
+			testNode := testTree.FindFirstNode(
+				xutils.NewPathType(test.startPath))
+			ns := xpath.NewNodesetDatum([]xutils.XpathNode{testNode})
+
+			actResult :=
+				verifyPcpToQueueMappings([]xpath.Datum{ns}).Boolean(
+					"(unused value)")
+			if test.expResult != actResult {
+				t.Fatalf("Unexpected result for %s: exp %t, got %t\n",
+					test.name, test.expResult, actResult)
+			}
+		})
+	}
+}
+
+func TestMapClassificationNotMixed(t *testing.T) {
+
+	tests := []qosProfileTestSpec{
+		{
+			name: "Profile with dscp-group only - PASS",
+			config: []xutils.PathType{
+				{"policy", "qos", "profile/name+prof1", "map",
+					DSCP_GRP_HIGH, "to+4"},
+			},
+			startPath: "/policy/qos/profile",
+			expResult: true,
+		},
+		{
+			name: "Profile with pcp only - PASS",
+			config: []xutils.PathType{
+				{"policy", "qos", "profile/name+prof1", "map",
+					"pcp/id+5", "to+4"},
+			},
+			startPath: "/policy/qos/profile",
+			expResult: true,
+		},
+		{
+			name: "Local profile with dscp-group and pcp - FAIL",
+			config: []xutils.PathType{
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "map", DSCP_GRP_HIGH, "to+4"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "map", "pcp/id+5", "to+4"},
+			},
+			startPath: "/policy/qos/name/shaper/profile",
+			expResult: false,
+		},
+		{
+			name: "Global profile with dscp-group and pcp - FAIL",
+			config: []xutils.PathType{
+				{"policy", "qos", "profile/name+prof1", "map",
+					DSCP_GRP_HIGH, "to+4"},
+				{"policy", "qos", "profile/name+prof1", "map",
+					"pcp/id+5", "to+4"},
+			},
+			startPath: "/policy/qos/profile",
+			expResult: false,
+		},
+		{
+			name: "Different profiles with dscp-group and pcp - PASS",
+			config: []xutils.PathType{
+				{"policy", "qos", "profile/name+prof1", "map",
+					DSCP_GRP_HIGH, "to+4"},
+				{"policy", "qos", "profile/name+prof2", "map",
+					"pcp/id+5", "to+4"},
+			},
+			startPath: "/policy/qos/profile",
+			expResult: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testTree := xpathtest.CreateTree(t, test.config)
+
+			testNode := testTree.FindFirstNode(
+				xutils.NewPathType(test.startPath))
+			ns := xpath.NewNodesetDatum([]xutils.XpathNode{testNode})
+
+			actResult :=
+				verifyMapClassificationNotMixed([]xpath.Datum{ns}).Boolean(
+					"(unused value)")
+			if test.expResult != actResult {
+				t.Fatalf("Unexpected result for %s: exp %t, got %t\n",
+					test.name, test.expResult, actResult)
+			}
+		})
+	}
+}
+
+func pcpIngressMapEntries(mapName string, numPcps int) []xutils.PathType {
+	entries := make([]xutils.PathType, 0, numPcps)
+	for pcp := 0; pcp < numPcps; pcp++ {
+		entries = append(entries, xutils.PathType{
+			"policy", "ingress-map/id+" + mapName,
+			"pcp/id+" + strconv.Itoa(pcp), "designation+" + strconv.Itoa(pcp)})
+	}
+	return entries
+}
+
+func TestIngressMapCoverage(t *testing.T) {
+
+	dscpGroups := []xutils.PathType{
+		{"resources", "group", "dscp-group/group-name+low", "dscp@0-31"},
+		{"resources", "group", "dscp-group/group-name+high", "dscp@32-63"},
+		{"resources", "group", "dscp-group/group-name+ef", "dscp@ef"},
+	}
+
+	tests := []qosProfileTestSpec{
+		{
+			name:      "All PCP values - PASS",
+			config:    pcpIngressMapEntries("im1", numPcpValues),
+			startPath: "/policy/ingress-map",
+			expResult: true,
+		},
+		{
+			name:      "Missing PCP value - FAIL",
+			config:    pcpIngressMapEntries("im1", numPcpValues-1),
+			startPath: "/policy/ingress-map",
+			expResult: false,
+		},
+		{
+			name: "All DSCP values - PASS",
+			config: append([]xutils.PathType{
+				{"policy", "ingress-map/id+im1", DSCP_GRP_LOW,
+					"designation+0"},
+				{"policy", "ingress-map/id+im1", DSCP_GRP_HIGH,
+					"designation+1"},
+			}, dscpGroups...),
+			startPath: "/policy/ingress-map",
+			expResult: true,
+		},
+		{
+			name: "Missing DSCP values - FAIL",
+			config: append([]xutils.PathType{
+				{"policy", "ingress-map/id+im1", DSCP_GRP_LOW,
+					"designation+0"},
+			}, dscpGroups...),
+			startPath: "/policy/ingress-map",
+			expResult: false,
+		},
+		{
+			name: "DSCP value in two groups - FAIL",
+			config: append([]xutils.PathType{
+				{"policy", "ingress-map/id+im1", DSCP_GRP_LOW,
+					"designation+0"},
+				{"policy", "ingress-map/id+im1", DSCP_GRP_HIGH,
+					"designation+1"},
+				{"policy", "ingress-map/id+im1", "dscp-group/group-name+ef",
+					"designation+2"},
+			}, dscpGroups...),
+			startPath: "/policy/ingress-map",
+			expResult: false,
+		},
+		{
+			name: "PCP and DSCP mixed - FAIL",
+			config: append(append([]xutils.PathType{
+				{"policy", "ingress-map/id+im1", DSCP_GRP_LOW,
+					"designation+0"},
+				{"policy", "ingress-map/id+im1", DSCP_GRP_HIGH,
+					"designation+1"},
+			}, dscpGroups...), pcpIngressMapEntries("im1", numPcpValues)...),
+			startPath: "/policy/ingress-map",
+			expResult: false,
+		},
+		{
+			name: "No classification - FAIL",
+			config: []xutils.PathType{
+				{"policy", "ingress-map/id+im1", "system-default%"},
+			},
+			startPath: "/policy/ingress-map",
+			expResult: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testTree := xpathtest.CreateTree(t, test.config)
+
+			testNode := testTree.FindFirstNode(
+				xutils.NewPathType(test.startPath))
+			ns := xpath.NewNodesetDatum([]xutils.XpathNode{testNode})
+
+			actResult :=
+				verifyIngressMapCoverage([]xpath.Datum{ns}).Boolean(
+					"(unused value)")
+			if test.expResult != actResult {
+				t.Fatalf("Unexpected result for %s: exp %t, got %t\n",
+					test.name, test.expResult, actResult)
+			}
+		})
+	}
+}
+
+func TestIngressMapDesignations(t *testing.T) {
+
+	tests := []qosProfileTestSpec{
+		{
+			name: "No shaper designations - PASS",
+			config: []xutils.PathType{
+				{"policy", "ingress-map/id+im1", "pcp/id+0", "designation+7"},
+			},
+			startPath: "/policy/ingress-map",
+			expResult: true,
+		},
+		{
+			name: "Designation out of range - FAIL",
+			config: []xutils.PathType{
+				{"policy", "ingress-map/id+im1", "pcp/id+0", "designation+8"},
+			},
+			startPath: "/policy/ingress-map",
+			expResult: false,
+		},
+		{
+			name: "Designation queue in local profile - PASS",
+			config: []xutils.PathType{
+				{"policy", "ingress-map/id+im1", "pcp/id+0", "designation+1"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"designation/id+1", "queue+3"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "queue/id+3", "traffic-class+2"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"traffic-class/id+2"},
+			},
+			startPath: "/policy/ingress-map",
+			expResult: true,
+		},
+		{
+			name: "Designation queue in referenced global profile - PASS",
+			config: []xutils.PathType{
+				{"policy", "ingress-map/id+im1", "pcp/id+0", "designation+1"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"designation/id+1", "queue+3"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"default+prof1"},
+				{"policy", "qos", "profile/name+prof1", "queue/id+3",
+					"traffic-class+2"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"traffic-class/id+2"},
+			},
+			startPath: "/policy/ingress-map",
+			expResult: true,
+		},
+		{
+			name: "Designation not defined by shaper - FAIL",
+			config: []xutils.PathType{
+				{"policy", "ingress-map/id+im1", "pcp/id+0", "designation+2"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"designation/id+1", "queue+3"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "queue/id+3", "traffic-class+2"},
+			},
+			startPath: "/policy/ingress-map",
+			expResult: false,
+		},
+		{
+			name: "Designation queue not in any profile - FAIL",
+			config: []xutils.PathType{
+				{"policy", "ingress-map/id+im1", "pcp/id+0", "designation+1"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"designation/id+1", "queue+4"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "queue/id+3", "traffic-class+2"},
+			},
+			startPath: "/policy/ingress-map",
+			expResult: false,
+		},
+		{
+			name: "Designation queue traffic-class invalid - FAIL",
+			config: []xutils.PathType{
+				{"policy", "ingress-map/id+im1", "pcp/id+0", "designation+1"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"designation/id+1", "queue+3"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "queue/id+3", "traffic-class+7"},
+			},
+			startPath: "/policy/ingress-map",
+			expResult: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testTree := xpathtest.CreateTree(t, test.config)
+
+			testNode := testTree.FindFirstNode(
+				xutils.NewPathType(test.startPath))
+			ns := xpath.NewNodesetDatum([]xutils.XpathNode{testNode})
+
+			actResult :=
+				verifyIngressMapDesignations([]xpath.Datum{ns}).Boolean(
+					"(unused value)")
+			if test.expResult != actResult {
+				t.Fatalf("Unexpected result for %s: exp %t, got %t\n",
+					test.name, test.expResult, actResult)
+			}
+		})
+	}
+}
+
+func TestIngressMapSystemDefaultUnique(t *testing.T) {
+
+	tests := []qosProfileTestSpec{
+		{
+			name: "No system default - PASS",
+			config: []xutils.PathType{
+				{"policy", "ingress-map/id+im1", "pcp/id+0", "designation+0"},
+				{"policy", "ingress-map/id+im2", "pcp/id+0", "designation+0"},
+			},
+			startPath: "/policy/ingress-map",
+			expResult: true,
+		},
+		{
+			name: "One system default - PASS",
+			config: []xutils.PathType{
+				{"policy", "ingress-map/id+im1", "system-default%"},
+				{"policy", "ingress-map/id+im2", "pcp/id+0", "designation+0"},
+			},
+			startPath: "/policy/ingress-map",
+			expResult: true,
+		},
+		{
+			name: "Two system defaults - FAIL",
+			config: []xutils.PathType{
+				{"policy", "ingress-map/id+im1", "system-default%"},
+				{"policy", "ingress-map/id+im2", "system-default%"},
+			},
+			startPath: "/policy/ingress-map",
+			expResult: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testTree := xpathtest.CreateTree(t, test.config)
+
+			testNode := testTree.FindFirstNode(
+				xutils.NewPathType(test.startPath))
+			ns := xpath.NewNodesetDatum([]xutils.XpathNode{testNode})
+
+			actResult :=
+				verifyIngressMapSystemDefaultUnique(
+					[]xpath.Datum{ns}).Boolean("(unused value)")
+			if test.expResult != actResult {
+				t.Fatalf("Unexpected result for %s: exp %t, got %t\n",
+					test.name, test.expResult, actResult)
+			}
+		})
+	}
+}
+
+func TestShaperRateHierarchy(t *testing.T) {
+
+	tests := []qosProfileTestSpec{
+		{
+			name: "No bandwidths configured - PASS",
+			config: []xutils.PathType{
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "queue/id+1", "traffic-class+1"},
+			},
+			startPath: "/policy/qos/name/shaper",
+			expResult: true,
+		},
+		{
+			name: "Absolute rates within hierarchy - PASS",
+			config: []xutils.PathType{
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"bandwidth+1Gbit"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"traffic-class/id+0", "bandwidth+500Mbit"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "bandwidth+100Mbit"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "traffic-class/id+0",
+					"bandwidth+100Mbit"},
+			},
+			startPath: "/policy/qos/name/shaper",
+			expResult: true,
+		},
+		{
+			name: "Byte rates within hierarchy - PASS",
+			config: []xutils.PathType{
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"bandwidth+125Mbps"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"traffic-class/id+0", "bandwidth+1Gbit"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "bandwidth+500Kbps"},
+			},
+			startPath: "/policy/qos/name/shaper",
+			expResult: true,
+		},
+		{
+			name: "Byte rate exceeds shaper - FAIL",
+			config: []xutils.PathType{
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"bandwidth+1Gbit"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"traffic-class/id+0", "bandwidth+200Mbps"},
+			},
+			startPath: "/policy/qos/name/shaper",
+			expResult: false,
+		},
+		{
+			name: "Shaper traffic-class exceeds shaper - FAIL",
+			config: []xutils.PathType{
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"bandwidth+1Gbit"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"traffic-class/id+0", "bandwidth+2Gbit"},
+			},
+			startPath: "/policy/qos/name/shaper",
+			expResult: false,
+		},
+		{
+			name: "Local profile exceeds shaper - FAIL",
+			config: []xutils.PathType{
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"bandwidth+100Mbit"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "bandwidth+200Mbit"},
+			},
+			startPath: "/policy/qos/name/shaper",
+			expResult: false,
+		},
+		{
+			name: "Global profile exceeds shaper - FAIL",
+			config: []xutils.PathType{
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"bandwidth+100Mbit"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"default+prof1"},
+				{"policy", "qos", "profile/name+prof1", "bandwidth+200Mbit"},
+			},
+			startPath: "/policy/qos/name/shaper",
+			expResult: false,
+		},
+		{
+			name: "Profile traffic-class exceeds percentage of shaper - FAIL",
+			config: []xutils.PathType{
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"bandwidth+1Gbit"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "bandwidth+10%"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "traffic-class/id+0",
+					"bandwidth+200Mbit"},
+			},
+			startPath: "/policy/qos/name/shaper",
+			expResult: false,
+		},
+		{
+			name: "Percentage with unknown interface speed - PASS",
+			config: []xutils.PathType{
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"bandwidth+50%"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "bandwidth+20Gbit"},
+			},
+			startPath: "/policy/qos/name/shaper",
+			expResult: true,
+		},
+		{
+			name: "Percentage of interface speed exceeded - FAIL",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "speed+10g"},
+				{"interfaces", "dataplane/tagnode+dp0s1", "policy",
+					"qos+pol1"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"bandwidth+50%"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "bandwidth+6Gbit"},
+			},
+			startPath: "/policy/qos/name/shaper",
+			expResult: false,
+		},
+		{
+			name: "Shaper exceeds speed of VIF's parent interface - FAIL",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "speed+1g"},
+				{"interfaces", "dataplane/tagnode+dp0s1", "vif/tagnode+10",
+					"policy", "qos+pol1"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"bandwidth+2Gbit"},
+			},
+			startPath: "/policy/qos/name/shaper",
+			expResult: false,
+		},
+		{
+			name: "Shaper within interface speed, auto ignored - PASS",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "speed+10g"},
+				{"interfaces", "dataplane/tagnode+dp0s1", "policy",
+					"qos+pol1"},
+				{"interfaces", "dataplane/tagnode+dp0s2", "speed+auto"},
+				{"interfaces", "dataplane/tagnode+dp0s2", "policy",
+					"qos+pol1"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"bandwidth+2Gbit"},
+			},
+			startPath: "/policy/qos/name/shaper",
+			expResult: true,
+		},
+		{
+			name: "Invalid bandwidth - FAIL",
+			config: []xutils.PathType{
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"bandwidth+fast"},
+			},
+			startPath: "/policy/qos/name/shaper",
+			expResult: false,
+		},
+		{
+			name: "Burst sizes within hierarchy - PASS",
+			config: []xutils.PathType{
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"burst+16000"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "burst+8000"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "traffic-class/id+0",
+					"burst+8000"},
+			},
+			startPath: "/policy/qos/name/shaper",
+			expResult: true,
+		},
+		{
+			name: "Profile traffic-class burst exceeds profile - FAIL",
+			config: []xutils.PathType{
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "burst+8000"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "traffic-class/id+0",
+					"burst+16000"},
+			},
+			startPath: "/policy/qos/name/shaper",
+			expResult: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testTree := xpathtest.CreateTree(t, test.config)
+
+			testNode := testTree.FindFirstNode(
+				xutils.NewPathType(test.startPath))
+			ns := xpath.NewNodesetDatum([]xutils.XpathNode{testNode})
+
+			actResult :=
+				verifyShaperRateHierarchy([]xpath.Datum{ns}).Boolean(
+					"(unused value)")
+			if test.expResult != actResult {
+				t.Fatalf("Unexpected result for %s: exp %t, got %t\n",
+					test.name, test.expResult, actResult)
+			}
+		})
+	}
+}
+
+func TestWredThresholds(t *testing.T) {
+
+	tests := []qosProfileTestSpec{
+		{
+			name: "No WRED map - PASS",
+			config: []xutils.PathType{
+				{"policy", "qos", "profile/name+prof1", "queue/id+1",
+					"traffic-class+1"},
+			},
+			startPath: "/policy/qos/profile/queue",
+			expResult: true,
+		},
+		{
+			name: "Colour thresholds ordered, no queue limit - PASS",
+			config: []xutils.PathType{
+				{"policy", "qos", "profile/name+prof1", "queue/id+1",
+					"wred-map", "drop-precedence/colour+green",
+					"min-threshold+32"},
+				{"policy", "qos", "profile/name+prof1", "queue/id+1",
+					"wred-map", "drop-precedence/colour+green",
+					"max-threshold+64"},
+				{"policy", "qos", "profile/name+prof1", "queue/id+1",
+					"wred-map", "drop-precedence/colour+green",
+					"mark-probability+10"},
+			},
+			startPath: "/policy/qos/profile/queue",
+			expResult: true,
+		},
+		{
+			name: "WRED map leaf is not an entry - PASS",
+			config: []xutils.PathType{
+				{"policy", "qos", "profile/name+prof1", "queue/id+1",
+					"wred-map", "filter-weight+10"},
+				{"policy", "qos", "profile/name+prof1", "queue/id+1",
+					"wred-map", "drop-precedence/colour+green",
+					"min-threshold+32"},
+				{"policy", "qos", "profile/name+prof1", "queue/id+1",
+					"wred-map", "drop-precedence/colour+green",
+					"max-threshold+64"},
+			},
+			startPath: "/policy/qos/profile/queue",
+			expResult: true,
+		},
+		{
+			name: "Min threshold equals max threshold - FAIL",
+			config: []xutils.PathType{
+				{"policy", "qos", "profile/name+prof1", "queue/id+1",
+					"wred-map", "drop-precedence/colour+green",
+					"min-threshold+64"},
+				{"policy", "qos", "profile/name+prof1", "queue/id+1",
+					"wred-map", "drop-precedence/colour+green",
+					"max-threshold+64"},
+			},
+			startPath: "/policy/qos/profile/queue",
+			expResult: false,
+		},
+		{
+			name: "Zero mark probability - FAIL",
+			config: []xutils.PathType{
+				{"policy", "qos", "profile/name+prof1", "queue/id+1",
+					"wred-map", "drop-precedence/colour+green",
+					"min-threshold+32"},
+				{"policy", "qos", "profile/name+prof1", "queue/id+1",
+					"wred-map", "drop-precedence/colour+green",
+					"max-threshold+64"},
+				{"policy", "qos", "profile/name+prof1", "queue/id+1",
+					"wred-map", "drop-precedence/colour+green",
+					"mark-probability+0"},
+			},
+			startPath: "/policy/qos/profile/queue",
+			expResult: false,
+		},
+		{
+			name: "Max threshold exceeds queue's own limit - FAIL",
+			config: []xutils.PathType{
+				{"policy", "qos", "profile/name+prof1", "queue/id+1",
+					"queue-limit+48"},
+				{"policy", "qos", "profile/name+prof1", "queue/id+1",
+					"wred-map", "drop-precedence/colour+green",
+					"min-threshold+32"},
+				{"policy", "qos", "profile/name+prof1", "queue/id+1",
+					"wred-map", "drop-precedence/colour+green",
+					"max-threshold+64"},
+			},
+			startPath: "/policy/qos/profile/queue",
+			expResult: false,
+		},
+		{
+			name: "Max threshold exceeds profile traffic-class limit - FAIL",
+			config: []xutils.PathType{
+				{"policy", "qos", "profile/name+prof1", "queue/id+1",
+					"traffic-class+1"},
+				{"policy", "qos", "profile/name+prof1", "queue/id+1",
+					"wred-map", "drop-precedence/colour+green",
+					"min-threshold+32"},
+				{"policy", "qos", "profile/name+prof1", "queue/id+1",
+					"wred-map", "drop-precedence/colour+green",
+					"max-threshold+64"},
+				{"policy", "qos", "profile/name+prof1",
+					"traffic-class/id+1", "queue-limit+48"},
+			},
+			startPath: "/policy/qos/profile/queue",
+			expResult: false,
+		},
+		{
+			name: "Max threshold within shaper traffic-class limit - PASS",
+			config: []xutils.PathType{
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "queue/id+1", "traffic-class+1"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "queue/id+1", "wred-map",
+					"drop-precedence/colour+green", "min-threshold+32"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "queue/id+1", "wred-map",
+					"drop-precedence/colour+green", "max-threshold+64"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"traffic-class/id+1", "queue-limit+64"},
+			},
+			startPath: "/policy/qos/name/shaper/profile/queue",
+			expResult: true,
+		},
+		{
+			name: "Max threshold exceeds shaper traffic-class limit - FAIL",
+			config: []xutils.PathType{
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "queue/id+1", "traffic-class+1"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "queue/id+1", "wred-map",
+					"drop-precedence/colour+green", "min-threshold+32"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "queue/id+1", "wred-map",
+					"drop-precedence/colour+green", "max-threshold+128"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"traffic-class/id+1", "queue-limit+64"},
+			},
+			startPath: "/policy/qos/name/shaper/profile/queue",
+			expResult: false,
+		},
+		{
+			name: "DSCP group used by profile map - PASS",
+			config: []xutils.PathType{
+				{"policy", "qos", "profile/name+prof1", "queue/id+1",
+					"wred-map", DSCP_GRP_HIGH, "min-threshold+32"},
+				{"policy", "qos", "profile/name+prof1", "queue/id+1",
+					"wred-map", DSCP_GRP_HIGH, "max-threshold+64"},
+				{"policy", "qos", "profile/name+prof1", "map",
+					DSCP_GRP_HIGH, "to+1"},
+			},
+			startPath: "/policy/qos/profile/queue",
+			expResult: true,
+		},
+		{
+			name: "DSCP group not used by profile map - FAIL",
+			config: []xutils.PathType{
+				{"policy", "qos", "profile/name+prof1", "queue/id+1",
+					"wred-map", DSCP_GRP_HIGH, "min-threshold+32"},
+				{"policy", "qos", "profile/name+prof1", "queue/id+1",
+					"wred-map", DSCP_GRP_HIGH, "max-threshold+64"},
+				{"policy", "qos", "profile/name+prof1", "map",
+					DSCP_GRP_LOW, "to+1"},
+			},
+			startPath: "/policy/qos/profile/queue",
+			expResult: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testTree := xpathtest.CreateTree(t, test.config)
+
+			testNode := testTree.FindFirstNode(
+				xutils.NewPathType(test.startPath))
+			ns := xpath.NewNodesetDatum([]xutils.XpathNode{testNode})
+
+			actResult :=
+				verifyWredThresholds([]xpath.Datum{ns}).Boolean(
+					"(unused value)")
+			if test.expResult != actResult {
+				t.Fatalf("Unexpected result for %s: exp %t, got %t\n",
+					test.name, test.expResult, actResult)
+			}
+		})
+	}
+}
+
+func TestQosPolicyLeafref(t *testing.T) {
+
+	tests := []qosProfileTestSpec{
+		{
+			name: "Policy exists - PASS",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "policy",
+					"qos+pol2"},
+				{"policy", "qos", "name/name+pol1", "shaper"},
+				{"policy", "qos", "name/name+pol2", "shaper"},
+			},
+			startPath: "/interfaces/dataplane/policy/qos",
+			expResult: true,
+		},
+		{
+			name: "Policy doesn't exist - FAIL",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "policy",
+					"qos+pol3"},
+				{"policy", "qos", "name/name+pol1", "shaper"},
+			},
+			startPath: "/interfaces/dataplane/policy/qos",
+			expResult: false,
+		},
+		{
+			name: "Global profile name is not a policy - FAIL",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "policy",
+					"qos+prof1"},
+				{"policy", "qos", "profile/name+prof1", "queue/id+1"},
+			},
+			startPath: "/interfaces/dataplane/policy/qos",
+			expResult: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testTree := xpathtest.CreateTree(t, test.config)
+
+			testNode := testTree.FindFirstNode(
+				xutils.NewPathType(test.startPath))
+			ns := xpath.NewNodesetDatum([]xutils.XpathNode{testNode})
+
+			actResult :=
+				isQosPolicyLeafref([]xpath.Datum{ns}).Boolean(
+					"(unused value)")
+			if test.expResult != actResult {
+				t.Fatalf("Unexpected result for %s: exp %t, got %t\n",
+					test.name, test.expResult, actResult)
+			}
+		})
+	}
+}
+
+func TestVifQosAttachment(t *testing.T) {
+
+	tests := []qosProfileTestSpec{
+		{
+			name: "Attachment on port, not VIF - PASS",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "policy",
+					"ingress-map+im1"},
+			},
+			startPath: "/interfaces/dataplane/policy/ingress-map",
+			expResult: true,
+		},
+		{
+			name: "VIF ingress-map, parent has shaper - PASS",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "vif/tagnode+10",
+					"policy", "ingress-map+im1"},
+				{"interfaces", "dataplane/tagnode+dp0s1", "policy",
+					"qos+pol1"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"bandwidth+1Gbit"},
+			},
+			startPath: "/interfaces/dataplane/vif/policy/ingress-map",
+			expResult: true,
+		},
+		{
+			name: "Bonding VIF qos, parent has shaper - PASS",
+			config: []xutils.PathType{
+				{"interfaces", "bonding/tagnode+dp0bond0", "vif/tagnode+10",
+					"policy", "qos+pol1"},
+				{"interfaces", "bonding/tagnode+dp0bond0", "policy",
+					"qos+pol1"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"bandwidth+1Gbit"},
+			},
+			startPath: "/interfaces/bonding/vif/policy/qos",
+			expResult: true,
+		},
+		{
+			name: "VIF egress-map, parent has no policy - FAIL",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "vif/tagnode+10",
+					"policy", "egress-map+em1"},
+			},
+			startPath: "/interfaces/dataplane/vif/policy/egress-map",
+			expResult: false,
+		},
+		{
+			name: "VIF ingress-map, parent policy doesn't exist - FAIL",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "vif/tagnode+10",
+					"policy", "ingress-map+im1"},
+				{"interfaces", "dataplane/tagnode+dp0s1", "policy",
+					"qos+pol2"},
+				{"policy", "qos", "name/name+pol1", "shaper"},
+			},
+			startPath: "/interfaces/dataplane/vif/policy/ingress-map",
+			expResult: false,
+		},
+		{
+			name: "VIF ingress-map, parent policy has no shaper - FAIL",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "vif/tagnode+10",
+					"policy", "ingress-map+im1"},
+				{"interfaces", "dataplane/tagnode+dp0s1", "policy",
+					"qos+pol1"},
+				{"policy", "qos", "name/name+pol1", "description+none"},
+			},
+			startPath: "/interfaces/dataplane/vif/policy/ingress-map",
+			expResult: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testTree := xpathtest.CreateTree(t, test.config)
+
+			testNode := testTree.FindFirstNode(
+				xutils.NewPathType(test.startPath))
+			ns := xpath.NewNodesetDatum([]xutils.XpathNode{testNode})
+
+			actResult :=
+				verifyVifQosAttachment([]xpath.Datum{ns}).Boolean(
+					"(unused value)")
+			if test.expResult != actResult {
+				t.Fatalf("Unexpected result for %s: exp %t, got %t\n",
+					test.name, test.expResult, actResult)
+			}
+		})
+	}
+}
+
+type qosUnusedTestSpec struct {
+	name      string
+	config    []xutils.PathType
+	expResult string
+}
+
+func TestQosUnused(t *testing.T) {
+
+	// Each config is run against both functions.
+	tests := []qosUnusedTestSpec{
+		{
+			name: "Nothing configured",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1"},
+			},
+			expResult: "|",
+		},
+		{
+			name: "Everything used",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "policy",
+					"ingress-map+im1"},
+				{"interfaces", "dataplane/tagnode+dp0s1", "vif/tagnode+10",
+					"policy", "ingress-map+im2"},
+				{"policy", "ingress-map/id+im1", DSCP_GRP_LOW,
+					"designation+0"},
+				{"policy", "ingress-map/id+im2", "pcp/id+0",
+					"designation+0"},
+				{"policy", "ingress-map/id+im3", "system-default%"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"default+profA"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"class/id+1", "profile+prof1"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "map", DSCP_GRP_HIGH, "to+1"},
+				{"policy", "qos", "profile/name+prof1", "queue/id+1",
+					"wred-map", DSCP_GRP_MED, "min-threshold+1"},
+				{"resources", "group", "dscp-group/group-name+low",
+					"dscp@0-9"},
+				{"resources", "group", "dscp-group/group-name+med",
+					"dscp@10-19"},
+				{"resources", "group", "dscp-group/group-name+high",
+					"dscp@20-29"},
+			},
+			expResult: "|",
+		},
+		{
+			name: "Unused profiles and groups",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "policy",
+					"ingress-map+im1"},
+				{"policy", "ingress-map/id+im1", DSCP_GRP_LOW,
+					"designation+0"},
+				{"policy", "ingress-map/id+im2", "pcp/id+0",
+					"designation+0"},
+				{"policy", "ingress-map/id+im3", "pcp/id+0",
+					"designation+0"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"default+profA"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "queue/id+1"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profB", "queue/id+1"},
+				{"policy", "qos", "name/name+pol2", "shaper",
+					"default+prof2"},
+				{"policy", "qos", "profile/name+prof1", "queue/id+1"},
+				{"policy", "qos", "profile/name+prof2", "queue/id+1"},
+				{"policy", "qos", "profile/name+prof3", "queue/id+1"},
+				{"resources", "group", "dscp-group/group-name+low",
+					"dscp@0-9"},
+				{"resources", "group", "dscp-group/group-name+med",
+					"dscp@10-19"},
+				{"resources", "group", "dscp-group/group-name+high",
+					"dscp@20-29"},
+			},
+			expResult: "pol1/profB prof1 prof3|high med",
+		},
+		{
+			name: "Global profile shadowed by local profile",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"default+prof1"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+prof1", "queue/id+1"},
+				{"policy", "qos", "name/name+pol2", "shaper",
+					"default+prof2"},
+				{"policy", "qos", "profile/name+prof1", "queue/id+1"},
+				{"policy", "qos", "profile/name+prof2", "queue/id+1"},
+			},
+			expResult: "prof1|",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testTree := xpathtest.CreateTree(t, test.config)
+
+			testNode := testTree.FindFirstNode(
+				xutils.NewPathType("/interfaces"))
+			ns := xpath.NewNodesetDatum([]xutils.XpathNode{testNode})
+
+			actResult := strings.Join([]string{
+				qosUnusedProfiles([]xpath.Datum{ns}).String(
+					"(unused value)"),
+				qosUnusedDscpGroups([]xpath.Datum{ns}).String(
+					"(unused value)"),
+			}, "|")
+			if test.expResult != actResult {
+				t.Fatalf("Unexpected result for %s: exp %q, got %q\n",
+					test.name, test.expResult, actResult)
+			}
+		})
+	}
+}
+
+type qosProfileSignatureTestSpec struct {
+	name      string
+	config    []xutils.PathType
+	startPath string
+	expResult string
+}
+
+func TestQosProfileSignature(t *testing.T) {
+
+	tests := []qosProfileSignatureTestSpec{
+		{
+			name: "Empty profile",
+			config: []xutils.PathType{
+				{"policy", "qos", "profile/name+prof1"},
+			},
+			startPath: "/policy/qos/profile",
+			expResult: "",
+		},
+		{
+			name: "Queues sorted numerically, missing weight",
+			config: []xutils.PathType{
+				{"policy", "qos", "profile/name+prof1", "queue/id+10",
+					"traffic-class+3"},
+				{"policy", "qos", "profile/name+prof1", "queue/id+10",
+					"weight+20"},
+				{"policy", "qos", "profile/name+prof1", "queue/id+2",
+					"traffic-class+0"},
+			},
+			startPath: "/policy/qos/profile",
+			expResult: "queue(2,0,-) queue(10,3,20)",
+		},
+		{
+			name: "Local profile with queues and map entries",
+			config: []xutils.PathType{
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "map", "pcp/id+5", "to+1"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "map", DSCP_GRP_LOW, "to+0"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "map", DSCP_GRP_HIGH, "to+1"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "queue/id+1", "traffic-class+1"},
+				{"policy", "qos", "name/name+pol1", "shaper",
+					"profile/name+profA", "queue/id+0", "traffic-class+0"},
+			},
+			startPath: "/policy/qos/name/shaper/profile",
+			expResult: "queue(0,0,-) queue(1,1,-) " +
+				"dscp-group(high,1) dscp-group(low,0) pcp(5,1)",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testTree := xpathtest.CreateTree(t, test.config)
+
+			testNode := testTree.FindFirstNode(
+				xutils.NewPathType(test.startPath))
+			ns := xpath.NewNodesetDatum([]xutils.XpathNode{testNode})
+
+			actResult :=
+				qosProfileSignature([]xpath.Datum{ns}).String(
+					"(unused value)")
+			if test.expResult != actResult {
+				t.Fatalf("Unexpected result for %s: exp %q, got %q\n",
+					test.name, test.expResult, actResult)
+			}
+		})
+	}
+}
+
+func TestQosProfileSignatureMatch(t *testing.T) {
+
+	// Profiles with the same contents configured in a different order
+	// have the same signature; a profile with a different traffic-class
+	// doesn't.
+	testTree := xpathtest.CreateTree(t, []xutils.PathType{
+		{"policy", "qos", "profile/name+prof1", "queue/id+1",
+			"traffic-class+1"},
+		{"policy", "qos", "profile/name+prof1", "queue/id+2",
+			"traffic-class+2"},
+		{"policy", "qos", "profile/name+prof2", "queue/id+2",
+			"traffic-class+2"},
+		{"policy", "qos", "profile/name+prof2", "queue/id+1",
+			"traffic-class+1"},
+		{"policy", "qos", "profile/name+prof3", "queue/id+1",
+			"traffic-class+1"},
+		{"policy", "qos", "profile/name+prof3", "queue/id+2",
+			"traffic-class+3"},
+	})
+
+	qosNode := testTree.FindFirstNode(xutils.NewPathType("/policy/qos"))
+	profileNodes := qosNode.XChildren(profileFilter, xutils.Unsorted)
+	if len(profileNodes) != 3 {
+		t.Fatalf("Expected 3 profiles, got %d\n", len(profileNodes))
+	}
+
+	signatures := make([]string, 0, len(profileNodes))
+	for _, profileNode := range profileNodes {
+		ns := xpath.NewNodesetDatum([]xutils.XpathNode{profileNode})
+		signatures = append(signatures,
+			qosProfileSignature([]xpath.Datum{ns}).String("(unused value)"))
+	}
+
+	if signatures[0] != signatures[1] {
+		t.Fatalf("Expected matching signatures, got %q and %q\n",
+			signatures[0], signatures[1])
+	}
+	if signatures[0] == signatures[2] {
+		t.Fatalf("Expected different signatures, got %q for both\n",
+			signatures[0])
+	}
+}
+
+// nsTestNode - test tree node with a namespace, which xpathtest trees don't
+// have.  Children are matched by comparing the filter with those built by
+// the common constructors, so a namespace-qualified filter only matches
+// nodes in its own namespace, whereas an unqualified one matches any.
+// Other XpathNode methods aren't used by the functions tested, so are left
+// to the (nil) embedded interface.
+type nsTestNode struct {
+	xutils.XpathNode
+	namespace string
+	name      string
+	value     string
+	parent    *nsTestNode
+	children  []*nsTestNode
+}
+
+const otherNamespace = "urn:example:other"
+
+func (n *nsTestNode) XParent() xutils.XpathNode {
+	if n.parent == nil {
+		return nil
+	}
+	return n.parent
+}
+
+func (n *nsTestNode) XRoot() xutils.XpathNode {
+	root := n
+	for root.parent != nil {
+		root = root.parent
+	}
+	return root
+}
+
+func (n *nsTestNode) XName() string  { return n.name }
+func (n *nsTestNode) XValue() string { return n.value }
+
+func (n *nsTestNode) XChildren(
+	filter xutils.XFilter,
+	_ xutils.SortSpec,
+) []xutils.XpathNode {
+	var children []xutils.XpathNode
+	for _, child := range n.children {
+		if filter == common.GetFilter("*") ||
+			filter == common.GetFilter(child.name) ||
+			filter == common.GetFilterWithNamespace(
+				child.namespace, child.name) {
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+// add - return the child with the given namespace, name and value, adding
+// it if not present.
+func (n *nsTestNode) add(namespace, name, value string) *nsTestNode {
+	for _, child := range n.children {
+		if child.namespace == namespace && child.name == name &&
+			child.value == value {
+			return child
+		}
+	}
+	child := &nsTestNode{
+		namespace: namespace,
+		name:      name,
+		value:     value,
+		parent:    n,
+	}
+	n.children = append(n.children, child)
+	return child
+}
+
+// addEntry - as add, for a list entry, which also gets its key leaf.
+func (n *nsTestNode) addEntry(namespace, name, key, value string) *nsTestNode {
+	entry := n.add(namespace, name, value)
+	entry.add(namespace, key, value)
+	return entry
+}
+
+// addNsProfile - add global profile 'name' under /policy/qos, in the given
+// namespace, with queue 1 and a map entry for dscp-group 'high'.  Returns
+// the queue and map entry.
+func addNsProfile(
+	root *nsTestNode,
+	namespace, name, trafficClass, to string,
+) (*nsTestNode, *nsTestNode) {
+	profile := root.add(common.PolicyNamespace, "policy", "").
+		add(common.QosNamespace, "qos", "").
+		addEntry(namespace, "profile", "name", name)
+
+	queue := profile.addEntry(namespace, "queue", "id", "1")
+	queue.add(namespace, "traffic-class", trafficClass)
+
+	mapEntry := profile.add(namespace, "map", "").
+		addEntry(namespace, "dscp-group", "group-name", "high")
+	mapEntry.add(namespace, "to", to)
+
+	return queue, mapEntry
+}
+
+// Nodes from other modules that have the same name as QoS nodes must be
+// ignored.  Each test has a QoS profile, 'prof1', and adds either a second
+// QoS profile or an identically named node from another module.
+func TestNamespaceQualifiedFilters(t *testing.T) {
+
+	tests := []struct {
+		name           string
+		other          func(root *nsTestNode)
+		expQueueResult bool
+		expMapResult   bool
+	}{
+		{
+			name: "Matching QoS profile",
+			other: func(root *nsTestNode) {
+				addNsProfile(root, common.QosNamespace, "prof2", "0", "3")
+			},
+			expQueueResult: true,
+			expMapResult:   true,
+		},
+		{
+			name: "Mismatched QoS profile",
+			other: func(root *nsTestNode) {
+				addNsProfile(root, common.QosNamespace, "prof2", "1", "4")
+			},
+			expQueueResult: false,
+			expMapResult:   false,
+		},
+		{
+			name: "Mismatched profile from other module",
+			other: func(root *nsTestNode) {
+				addNsProfile(root, otherNamespace, "prof2", "1", "4")
+			},
+			expQueueResult: true,
+			expMapResult:   true,
+		},
+		{
+			name: "Mismatched QoS profile, QoS ingress-map",
+			other: func(root *nsTestNode) {
+				addNsProfile(root, common.QosNamespace, "prof2", "1", "4")
+				root.add(common.PolicyNamespace, "policy", "").
+					addEntry(common.QosNamespace, "ingress-map", "id",
+						"im1")
+			},
+			expQueueResult: true,
+			expMapResult:   false,
+		},
+		{
+			name: "Mismatched QoS profile, ingress-map from other module",
+			other: func(root *nsTestNode) {
+				addNsProfile(root, common.QosNamespace, "prof2", "1", "4")
+				root.add(common.PolicyNamespace, "policy", "").
+					addEntry(otherNamespace, "ingress-map", "id", "im1")
+			},
+			expQueueResult: false,
+			expMapResult:   false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root := &nsTestNode{}
+			queue, mapEntry := addNsProfile(
+				root, common.QosNamespace, "prof1", "0", "3")
+			test.other(root)
+
+			ns := xpath.NewNodesetDatum([]xutils.XpathNode{queue})
+			actResult :=
+				verifyQueueIdAndTrafficClass([]xpath.Datum{ns}).Boolean(
+					"(unused value)")
+			if test.expQueueResult != actResult {
+				t.Fatalf("Unexpected queue result for %s: exp %t, got %t\n",
+					test.name, test.expQueueResult, actResult)
+			}
+
+			ns = xpath.NewNodesetDatum([]xutils.XpathNode{mapEntry})
+			actResult =
+				verifyDscpGroupToQueueMappings([]xpath.Datum{ns}).Boolean(
+					"(unused value)")
+			if test.expMapResult != actResult {
+				t.Fatalf("Unexpected map result for %s: exp %t, got %t\n",
+					test.name, test.expMapResult, actResult)
+			}
+		})
+	}
+}
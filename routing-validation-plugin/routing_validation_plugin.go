@@ -0,0 +1,161 @@
+// Copyright (c) 2021, AT&T Intellectual Property Inc.
+// All rights reserved.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"net"
+
+	"github.com/danos/xpath-plugins/common"
+	"github.com/danos/yang/xpath"
+	"github.com/danos/yang/xpath/xutils"
+)
+
+var RegistrationData = []xpath.CustomFunctionInfo{
+	{
+		Name:          "verify-static-route-next-hop",
+		FnPtr:         verifyStaticRouteNextHop,
+		Args:          []xpath.DatumTypeChecker{xpath.TypeIsNodeset},
+		RetType:       xpath.TypeIsBool,
+		DefaultRetVal: xpath.NewBoolDatum(false),
+	},
+	{
+		Name:          "verify-static-route-next-hop-interface",
+		FnPtr:         verifyStaticRouteNextHopInterface,
+		Args:          []xpath.DatumTypeChecker{xpath.TypeIsNodeset},
+		RetType:       xpath.TypeIsBool,
+		DefaultRetVal: xpath.NewBoolDatum(false),
+	},
+}
+
+// Next-hop interfaces must be able to route, so L2 only interface types
+// are ignored.  VIFs on these are still allowed.
+var l2InterfaceTypes = []string{"switch", "backplane"}
+
+// Filters used to find required nodes. Values never change, so create once
+// and reuse.
+var interfaceFilter = common.GetFilter("interface")
+
+// verifyStaticRouteNextHop - implementation of
+// verify-static-route-next-hop(<nodeset>)
+//
+// Applied to a static route next-hop, in the default or any named routing
+// instance, eg:
+//
+//   protocols static route 10.0.0.0/24 next-hop 192.0.2.1 interface dp0s1
+//
+// Checks that:
+//
+//   - the next-hop address is the same address family as the route prefix
+//   - the next-hop address is not inside the route prefix (other than for
+//     a default route), as the route could then only resolve via itself
+//   - the next-hop interface, if set, exists, may be a VIF, and is bound
+//     to the routing instance the route is configured in
+//
+//   configd:must "verify-static-route-next-hop(.)"
+//
+func verifyStaticRouteNextHop(
+	args []xpath.Datum,
+) (retBool xpath.Datum) {
+
+	// Function has flexibility to be applied to '.' or any other node,
+	// rather than just the current node, but should only be applied to
+	// a single node.  So, if nodeset is empty or has multiple entries,
+	// return false.
+	ns0 := args[0].Nodeset("verify-static-route-next-hop()")
+	if len(ns0) != 1 {
+		return xpath.NewBoolDatum(false)
+	}
+	nextHopNode := ns0[0]
+
+	if !isNextHopValidForPrefix(
+		nextHopNode.XParent().XValue(), nextHopNode.XValue()) {
+		return xpath.NewBoolDatum(false)
+	}
+
+	intfName, ok := common.GetSingleChildValue(nextHopNode, interfaceFilter)
+	if !ok {
+		return xpath.NewBoolDatum(true)
+	}
+	return xpath.NewBoolDatum(isNextHopInterfaceValid(nextHopNode, intfName))
+}
+
+// verifyStaticRouteNextHopInterface - implementation of
+// verify-static-route-next-hop-interface(<nodeset>)
+//
+// Applied to a static interface-route next-hop-interface, in the default or
+// any named routing instance, and checks the interface, which may be a VIF,
+// exists and is bound to the same routing instance as the route:
+//
+//   configd:must "verify-static-route-next-hop-interface(.)"
+//
+func verifyStaticRouteNextHopInterface(
+	args []xpath.Datum,
+) (retBool xpath.Datum) {
+
+	// Function has flexibility to be applied to '.' or any other node,
+	// rather than just the current node, but should only be applied to
+	// a single node.  So, if nodeset is empty or has multiple entries,
+	// return false.
+	ns0 := args[0].Nodeset("verify-static-route-next-hop-interface()")
+	if len(ns0) != 1 {
+		return xpath.NewBoolDatum(false)
+	}
+	intfNode := ns0[0]
+
+	return xpath.NewBoolDatum(
+		isNextHopInterfaceValid(intfNode, intfNode.XValue()))
+}
+
+// isNextHopInterfaceValid - true if the named interface exists and is in
+// the routing instance the route containing node is configured in.
+func isNextHopInterfaceValid(node xutils.XpathNode, intfName string) bool {
+	root := node.XRoot()
+	if _, ok := common.GetInterfaceNode(
+		root, intfName, l2InterfaceTypes); !ok {
+		return false
+	}
+	return common.GetInterfaceRoutingInstance(root, intfName) ==
+		getRouteRoutingInstance(node)
+}
+
+// getRouteRoutingInstance - return the name of the routing instance a
+// route is configured in, ie the routing-instance list entry it is under,
+// or the default routing instance for routes under /protocols.
+func getRouteRoutingInstance(node xutils.XpathNode) string {
+	for ancestor := node.XParent(); ancestor != nil; ancestor =
+		ancestor.XParent() {
+		if ancestor.XName() == "routing-instance" {
+			return ancestor.XValue()
+		}
+	}
+	return common.DefaultRoutingInstance
+}
+
+// isNextHopValidForPrefix - true if the next-hop address is of the same
+// family as the route prefix, and, unless the route is a default route,
+// outside the prefix.
+func isNextHopValidForPrefix(prefix, nextHop string) bool {
+	_, prefixNet, err := net.ParseCIDR(prefix)
+	if err != nil {
+		return false
+	}
+	nextHopRange, ok := common.ParseAddressRange(nextHop)
+	if !ok || nextHopRange.Size() != 1 {
+		return false
+	}
+	nextHopAddr := nextHopRange.Start
+
+	// IPv4 addresses and prefixes are both held in their 4 byte form.
+	if len(nextHopAddr) != len(prefixNet.IP) {
+		return false
+	}
+
+	// A default route covers every address, including the next-hop.
+	if ones, _ := prefixNet.Mask.Size(); ones == 0 {
+		return true
+	}
+	return !prefixNet.Contains(nextHopAddr)
+}
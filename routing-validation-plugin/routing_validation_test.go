@@ -0,0 +1,301 @@
+// Copyright (c) 2021, AT&T Intellectual Property. All rights reserved.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"testing"
+
+	"github.com/danos/yang/xpath"
+	"github.com/danos/yang/xpath/xpathtest"
+	"github.com/danos/yang/xpath/xutils"
+)
+
+type routingTestSpec struct {
+	name      string
+	config    []xutils.PathType
+	startPath string
+	expResult bool
+}
+
+var routingInterfaces = []xutils.PathType{
+	{"interfaces", "dataplane/tagnode+dp0s1"},
+	{"interfaces", "dataplane/tagnode+dp0s2", "vif/tagnode+10"},
+	{"interfaces", "switch/name+sw0", "vif/tagnode+20"},
+}
+
+func TestIsNextHopValidForPrefix(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		prefix    string
+		nextHop   string
+		expResult bool
+	}{
+		{"IPv4 outside prefix", "10.0.0.0/24", "192.0.2.1", true},
+		{"IPv4 inside prefix", "10.0.0.0/24", "10.0.0.1", false},
+		{"IPv4 host route to next-hop", "192.0.2.1/32", "192.0.2.1", false},
+		{"IPv4 default route", "0.0.0.0/0", "192.0.2.1", true},
+		{"IPv6 outside prefix", "2001:db8:1::/48", "2001:db8:2::1", true},
+		{"IPv6 inside prefix", "2001:db8:1::/48", "2001:db8:1::1", false},
+		{"IPv6 default route", "::/0", "fe80::1", true},
+		{"IPv4 prefix, IPv6 next-hop", "10.0.0.0/24", "2001:db8::1", false},
+		{"IPv6 prefix, IPv4 next-hop", "2001:db8::/32", "192.0.2.1", false},
+		{"IPv6 default, IPv4 next-hop", "::/0", "192.0.2.1", false},
+		{"Next-hop range", "10.0.0.0/24", "192.0.2.1-192.0.2.2", false},
+		{"Invalid prefix", "10.0.0.0/33", "192.0.2.1", false},
+		{"Invalid next-hop", "10.0.0.0/24", "192.0.2.256", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actResult := isNextHopValidForPrefix(test.prefix, test.nextHop)
+			if test.expResult != actResult {
+				t.Fatalf("Unexpected result for %s: exp %t, got %t\n",
+					test.name, test.expResult, actResult)
+			}
+		})
+	}
+}
+
+func TestStaticRouteNextHop(t *testing.T) {
+
+	tests := []routingTestSpec{
+		{
+			name: "Default instance, no interface - PASS",
+			config: []xutils.PathType{
+				{"protocols", "static", "route/tagnode+10.0.0.0/24",
+					"next-hop/tagnode+192.0.2.1"},
+			},
+			startPath: "/protocols/static/route/next-hop",
+			expResult: true,
+		},
+		{
+			name: "Default instance, next-hop in prefix - FAIL",
+			config: []xutils.PathType{
+				{"protocols", "static", "route/tagnode+10.0.0.0/24",
+					"next-hop/tagnode+10.0.0.254"},
+			},
+			startPath: "/protocols/static/route/next-hop",
+			expResult: false,
+		},
+		{
+			name: "Default instance, IPv6 next-hop for IPv4 route - FAIL",
+			config: []xutils.PathType{
+				{"protocols", "static", "route/tagnode+10.0.0.0/24",
+					"next-hop/tagnode+2001:db8::1"},
+			},
+			startPath: "/protocols/static/route/next-hop",
+			expResult: false,
+		},
+		{
+			name: "Default instance, VIF interface - PASS",
+			config: []xutils.PathType{
+				{"protocols", "static", "route6/tagnode+2001:db8:1::/48",
+					"next-hop/tagnode+fe80::1", "interface+dp0s2.10"},
+			},
+			startPath: "/protocols/static/route6/next-hop",
+			expResult: true,
+		},
+		{
+			name: "Default instance, unknown interface - FAIL",
+			config: []xutils.PathType{
+				{"protocols", "static", "route/tagnode+10.0.0.0/24",
+					"next-hop/tagnode+192.0.2.1", "interface+dp0s9"},
+			},
+			startPath: "/protocols/static/route/next-hop",
+			expResult: false,
+		},
+		{
+			name: "Named instance, interface - PASS",
+			config: []xutils.PathType{
+				{"routing", "routing-instance/instance-name+blue",
+					"interface/name+dp0s1"},
+				{"routing", "routing-instance/instance-name+blue",
+					"protocols", "static", "route/tagnode+10.0.0.0/24",
+					"next-hop/tagnode+192.0.2.1", "interface+dp0s1"},
+			},
+			startPath: "/routing/routing-instance/protocols/static/route/" +
+				"next-hop",
+			expResult: true,
+		},
+		{
+			name: "Named instance, interface in default instance - FAIL",
+			config: []xutils.PathType{
+				{"routing", "routing-instance/instance-name+blue",
+					"protocols", "static", "route/tagnode+10.0.0.0/24",
+					"next-hop/tagnode+192.0.2.1", "interface+dp0s1"},
+			},
+			startPath: "/routing/routing-instance/protocols/static/route/" +
+				"next-hop",
+			expResult: false,
+		},
+		{
+			name: "Named instance, interface in other instance - FAIL",
+			config: []xutils.PathType{
+				{"routing", "routing-instance/instance-name+blue",
+					"protocols", "static", "route/tagnode+10.0.0.0/24",
+					"next-hop/tagnode+192.0.2.1", "interface+dp0s1"},
+				{"routing", "routing-instance/instance-name+red",
+					"interface/name+dp0s1"},
+			},
+			startPath: "/routing/routing-instance/protocols/static/route/" +
+				"next-hop",
+			expResult: false,
+		},
+		{
+			name: "Default instance, interface in named instance - FAIL",
+			config: []xutils.PathType{
+				{"routing", "routing-instance/instance-name+blue",
+					"interface/name+dp0s1"},
+				{"protocols", "static", "route/tagnode+10.0.0.0/24",
+					"next-hop/tagnode+192.0.2.1", "interface+dp0s1"},
+			},
+			startPath: "/protocols/static/route/next-hop",
+			expResult: false,
+		},
+		{
+			name: "Named instance, unknown VIF - FAIL",
+			config: []xutils.PathType{
+				{"routing", "routing-instance/instance-name+blue",
+					"protocols", "static", "route/tagnode+10.0.0.0/24",
+					"next-hop/tagnode+192.0.2.1", "interface+dp0s2.99"},
+			},
+			startPath: "/routing/routing-instance/protocols/static/route/" +
+				"next-hop",
+			expResult: false,
+		},
+		{
+			name: "Named instance, next-hop in prefix - FAIL",
+			config: []xutils.PathType{
+				{"routing", "routing-instance/instance-name+blue",
+					"protocols", "static", "route/tagnode+10.0.0.0/8",
+					"next-hop/tagnode+10.1.1.1"},
+			},
+			startPath: "/routing/routing-instance/protocols/static/route/" +
+				"next-hop",
+			expResult: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testTree := xpathtest.CreateTree(t,
+				append(test.config, routingInterfaces...))
+
+			testNode := testTree.FindFirstNode(
+				xutils.NewPathType(test.startPath))
+			ns := xpath.NewNodesetDatum([]xutils.XpathNode{testNode})
+
+			actResult :=
+				verifyStaticRouteNextHop([]xpath.Datum{ns}).Boolean(
+					"(unused value)")
+			if test.expResult != actResult {
+				t.Fatalf("Unexpected result for %s: exp %t, got %t\n",
+					test.name, test.expResult, actResult)
+			}
+		})
+	}
+}
+
+func TestStaticRouteNextHopInterface(t *testing.T) {
+
+	tests := []routingTestSpec{
+		{
+			name: "Default instance, interface - PASS",
+			config: []xutils.PathType{
+				{"protocols", "static", "interface-route/tagnode+10.0.0.0/24",
+					"next-hop-interface/tagnode+dp0s1"},
+			},
+			startPath: "/protocols/static/interface-route/next-hop-interface",
+			expResult: true,
+		},
+		{
+			name: "Default instance, switch interface - FAIL",
+			config: []xutils.PathType{
+				{"protocols", "static", "interface-route/tagnode+10.0.0.0/24",
+					"next-hop-interface/tagnode+sw0"},
+			},
+			startPath: "/protocols/static/interface-route/next-hop-interface",
+			expResult: false,
+		},
+		{
+			name: "Default instance, switch VIF - PASS",
+			config: []xutils.PathType{
+				{"protocols", "static", "interface-route/tagnode+10.0.0.0/24",
+					"next-hop-interface/tagnode+sw0.20"},
+			},
+			startPath: "/protocols/static/interface-route/next-hop-interface",
+			expResult: true,
+		},
+		{
+			name: "Named instance, VIF - PASS",
+			config: []xutils.PathType{
+				{"routing", "routing-instance/instance-name+blue",
+					"interface/name+dp0s2.10"},
+				{"routing", "routing-instance/instance-name+blue",
+					"protocols", "static",
+					"interface-route6/tagnode+2001:db8::/32",
+					"next-hop-interface/tagnode+dp0s2.10"},
+			},
+			startPath: "/routing/routing-instance/protocols/static/" +
+				"interface-route6/next-hop-interface",
+			expResult: true,
+		},
+		{
+			name: "Named instance, VIF in default instance - FAIL",
+			config: []xutils.PathType{
+				{"routing", "routing-instance/instance-name+blue",
+					"protocols", "static",
+					"interface-route6/tagnode+2001:db8::/32",
+					"next-hop-interface/tagnode+dp0s2.10"},
+			},
+			startPath: "/routing/routing-instance/protocols/static/" +
+				"interface-route6/next-hop-interface",
+			expResult: false,
+		},
+		{
+			name: "Default instance, VIF in named instance - FAIL",
+			config: []xutils.PathType{
+				{"routing", "routing-instance/instance-name+blue",
+					"interface/name+dp0s2.10"},
+				{"protocols", "static", "interface-route/tagnode+10.0.0.0/24",
+					"next-hop-interface/tagnode+dp0s2.10"},
+			},
+			startPath: "/protocols/static/interface-route/next-hop-interface",
+			expResult: false,
+		},
+		{
+			name: "Named instance, unknown interface - FAIL",
+			config: []xutils.PathType{
+				{"routing", "routing-instance/instance-name+blue",
+					"protocols", "static",
+					"interface-route/tagnode+10.0.0.0/24",
+					"next-hop-interface/tagnode+dp0s9"},
+			},
+			startPath: "/routing/routing-instance/protocols/static/" +
+				"interface-route/next-hop-interface",
+			expResult: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testTree := xpathtest.CreateTree(t,
+				append(test.config, routingInterfaces...))
+
+			testNode := testTree.FindFirstNode(
+				xutils.NewPathType(test.startPath))
+			ns := xpath.NewNodesetDatum([]xutils.XpathNode{testNode})
+
+			actResult :=
+				verifyStaticRouteNextHopInterface([]xpath.Datum{ns}).Boolean(
+					"(unused value)")
+			if test.expResult != actResult {
+				t.Fatalf("Unexpected result for %s: exp %t, got %t\n",
+					test.name, test.expResult, actResult)
+			}
+		})
+	}
+}
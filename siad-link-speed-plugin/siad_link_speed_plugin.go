@@ -6,6 +6,7 @@
 package main
 
 import (
+	"math"
 	"strconv"
 	"strings"
 
@@ -30,15 +31,48 @@ var RegistrationData = []xpath.CustomFunctionInfo{
 		RetType:       xpath.TypeIsBool,
 		DefaultRetVal: xpath.NewBoolDatum(false),
 	},
+	{
+		Name:  "verify-siad-link-speed-strict",
+		FnPtr: verifySiadLinkSpeedStrict,
+		Args: []xpath.DatumTypeChecker{
+			xpath.TypeIsNumber,
+			xpath.TypeIsNumber,
+			xpath.TypeIsNodeset},
+		RetType:       xpath.TypeIsBool,
+		DefaultRetVal: xpath.NewBoolDatum(false),
+	},
+	{
+		Name:          "speed-to-mbps",
+		FnPtr:         speedToMbps,
+		Args:          []xpath.DatumTypeChecker{xpath.TypeIsNodeset},
+		RetType:       xpath.TypeIsNumber,
+		DefaultRetVal: xpath.NewNumDatum(math.NaN()),
+	},
+	{
+		Name:  "speed-at-least",
+		FnPtr: speedAtLeast,
+		Args: []xpath.DatumTypeChecker{
+			xpath.TypeIsNodeset,
+			xpath.TypeIsLiteral},
+		RetType:       xpath.TypeIsBool,
+		DefaultRetVal: xpath.NewBoolDatum(false),
+	},
+}
+
+// Speeds permitted on SIAD dp0xe interfaces, other than 'auto'.
+var siadSpeeds = []common.Speed{
+	common.NewSpeed(10000),
+	common.NewSpeed(25000),
 }
 
 // Filters used to find required nodes. Values never change, so create once
-// and reuse.
-var intfFilter = common.GetFilter("interfaces")
-var dataplaneFilter = common.GetFilter("dataplane")
-var tagnodeFilter = common.GetFilter("tagnode")
-var disableFilter = common.GetFilter("disable")
-var speedFilter = common.GetFilter("speed")
+// and reuse.  Dataplane nodes are qualified with the 'dp:' prefix used by
+// the original must statement.
+var intfFilter = common.GetPrefixedFilter("if:interfaces")
+var dataplaneFilter = common.GetPrefixedFilter("dp:dataplane")
+var tagnodeFilter = common.GetPrefixedFilter("dp:tagnode")
+var disableFilter = common.GetPrefixedFilter("dp:disable")
+var speedFilter = common.GetPrefixedFilter("dp:speed")
 
 func getIntfNameAndIdForType(
 	intfNode xutils.XpathNode,
@@ -106,9 +140,31 @@ func getIntfNameAndIdForType(
 func verifySiadLinkSpeed(
 	args []xpath.Datum,
 ) (retBool xpath.Datum) {
+	return verifySiadLinkSpeedInternal(args, "verify-siad-link-speed()", false)
+}
+
+// verifySiadLinkSpeedStrict - as verifySiadLinkSpeed, but without the
+// benefit of the doubt.
+//
+// A disabled peer interface is skipped rather than ending the check, so the
+// remaining interfaces in the group are still compared with the current one.
+// Structural anomalies (multiple /interfaces nodes, a peer with no single
+// speed node) cause the check to fail rather than pass.
+func verifySiadLinkSpeedStrict(
+	args []xpath.Datum,
+) (retBool xpath.Datum) {
+	return verifySiadLinkSpeedInternal(
+		args, "verify-siad-link-speed-strict()", true)
+}
 
-	startIntfID := int(args[0].Number("verify-siad-link-speed()"))
-	endIntfID := int(args[1].Number("verify-siad-link-speed()"))
+func verifySiadLinkSpeedInternal(
+	args []xpath.Datum,
+	fnName string,
+	strict bool,
+) (retBool xpath.Datum) {
+
+	startIntfID := int(args[0].Number(fnName))
+	endIntfID := int(args[1].Number(fnName))
 
 	// If only one interface in range
 	if endIntfID <= startIntfID {
@@ -119,7 +175,7 @@ func verifySiadLinkSpeed(
 	// rather than just the current node, but should only be applied to
 	// a single node.  So, if nodeset is empty or has multiple entries,
 	// return false.
-	ns0 := args[2].Nodeset("verify-siad-link-speed()")
+	ns0 := args[2].Nodeset(fnName)
 	if len(ns0) != 1 {
 		return xpath.NewBoolDatum(false)
 	}
@@ -145,14 +201,18 @@ func verifySiadLinkSpeed(
 		return xpath.NewBoolDatum(true)
 	}
 
-	// Return true if interface speed (current node) is auto
-	curSpeed := curSpeedNode.XValue()
-	if curSpeed == "auto" {
+	// Return true if interface speed (current node) is auto.  Speeds are
+	// normalised so that eg '10g' and '10000' are treated as equal.
+	curSpeed, ok := common.ParseSpeed(curSpeedNode.XValue())
+	if !ok {
+		return xpath.NewBoolDatum(false)
+	}
+	if curSpeed.IsAuto() {
 		return xpath.NewBoolDatum(true)
 	}
 
 	// Return false if interface speed is not 10g or 25g
-	if curSpeed != "10g" && curSpeed != "25g" {
+	if !isSiadSpeed(curSpeed) {
 		return xpath.NewBoolDatum(false)
 	}
 
@@ -160,7 +220,7 @@ func verifySiadLinkSpeed(
 	// is not disabled and speed isn't either auto or same as current node.
 	intfNodes := curSpeedNode.XRoot().XChildren(intfFilter, xutils.Sorted)
 	if intfNodes == nil || len(intfNodes) > 1 {
-		return xpath.NewBoolDatum(true)
+		return xpath.NewBoolDatum(!strict)
 	}
 
 	dpNodes := intfNodes[0].XChildren(dataplaneFilter, xutils.Sorted)
@@ -176,16 +236,25 @@ func verifySiadLinkSpeed(
 
 		_, disabled := common.GetSingleChildValue(otherIntfNode, disableFilter)
 		if disabled {
+			if strict {
+				// Disabled peers don't constrain us, but others might.
+				continue
+			}
 			return xpath.NewBoolDatum(true)
 		}
-		otherIntfSpeed, ok := common.GetSingleChildValue(
+		otherIntfSpeedVal, ok := common.GetSingleChildValue(
 			otherIntfNode, speedFilter)
 		if !ok {
 			// Better to allow if node is missing or we risk an unexpected
-			// problem making valid configs invalid.
-			return xpath.NewBoolDatum(true)
+			// problem making valid configs invalid.  Strict mode says
+			// otherwise.
+			return xpath.NewBoolDatum(!strict)
 		}
-		if otherIntfSpeed != "auto" && otherIntfSpeed != curSpeed {
+		otherIntfSpeed, ok := common.ParseSpeed(otherIntfSpeedVal)
+		if !ok {
+			return xpath.NewBoolDatum(false)
+		}
+		if !otherIntfSpeed.IsAuto() && otherIntfSpeed.Compare(curSpeed) != 0 {
 			return xpath.NewBoolDatum(false)
 		}
 	}
@@ -193,3 +262,70 @@ func verifySiadLinkSpeed(
 	// Return true
 	return xpath.NewBoolDatum(true)
 }
+
+func isSiadSpeed(speed common.Speed) bool {
+	for _, siadSpeed := range siadSpeeds {
+		if speed.Compare(siadSpeed) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// speedToMbps - implementation of speed-to-mbps(<nodeset>)
+//
+// Returns the speed of the node provided in Mbit/s, so speeds can be
+// compared numerically regardless of how they were entered ("10g", "10000"
+// and "10G" all return 10000).  Returns NaN for 'auto' or an unparseable
+// speed, in the same way as number() does for a non-numeric string, so any
+// numeric comparison against it is false.
+func speedToMbps(
+	args []xpath.Datum,
+) (retNum xpath.Datum) {
+
+	// Function has flexibility to be applied to '.' or any other node,
+	// rather than just the current node, but should only be applied to
+	// a single node.  So, if nodeset is empty or has multiple entries,
+	// return NaN.
+	ns0 := args[0].Nodeset("speed-to-mbps()")
+	if len(ns0) != 1 {
+		return xpath.NewNumDatum(math.NaN())
+	}
+
+	speed, ok := common.ParseSpeed(ns0[0].XValue())
+	if !ok || speed.IsAuto() {
+		return xpath.NewNumDatum(math.NaN())
+	}
+	return xpath.NewNumDatum(float64(speed.Mbps()))
+}
+
+// speedAtLeast - implementation of speed-at-least(<nodeset>, <speed>)
+//
+// Returns true if the speed of the node provided is at least the given
+// speed, eg speed-at-least(., '25g').  'auto' is treated as being faster
+// than any fixed speed (see common.Speed).  Returns false if either speed
+// cannot be parsed.
+func speedAtLeast(
+	args []xpath.Datum,
+) (retBool xpath.Datum) {
+
+	// Function has flexibility to be applied to '.' or any other node,
+	// rather than just the current node, but should only be applied to
+	// a single node.  So, if nodeset is empty or has multiple entries,
+	// return false.
+	ns0 := args[0].Nodeset("speed-at-least()")
+	if len(ns0) != 1 {
+		return xpath.NewBoolDatum(false)
+	}
+
+	speed, ok := common.ParseSpeed(ns0[0].XValue())
+	if !ok {
+		return xpath.NewBoolDatum(false)
+	}
+	minSpeed, ok := common.ParseSpeed(args[1].String("speed-at-least()"))
+	if !ok {
+		return xpath.NewBoolDatum(false)
+	}
+
+	return xpath.NewBoolDatum(speed.Compare(minSpeed) >= 0)
+}
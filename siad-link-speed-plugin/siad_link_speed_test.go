@@ -5,6 +5,7 @@
 package main
 
 import (
+	"math"
 	"testing"
 
 	"github.com/danos/yang/xpath"
@@ -159,6 +160,23 @@ func TestSiadLinkSpeedValidation(t *testing.T) {
 			startPath: "/interfaces/dataplane/speed",
 			expResult: true,
 		},
+		{
+			name: "2 dp0xe interfaces in range, same speed, different form - PASS",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0xe22", "speed+10G"},
+				{"interfaces", "dataplane/tagnode+dp0xe23", "speed+10000"},
+			},
+			startPath: "/interfaces/dataplane/speed",
+			expResult: true,
+		},
+		{
+			name: "dp0xe interface of interest, invalid speed - FAIL",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0xe22", "speed+fast"},
+			},
+			startPath: "/interfaces/dataplane/speed",
+			expResult: false,
+		},
 	}
 
 	for _, test := range tests {
@@ -182,3 +200,212 @@ func TestSiadLinkSpeedValidation(t *testing.T) {
 		})
 	}
 }
+
+type siadLinkSpeedStrictTestSpec struct {
+	name            string
+	config          []xutils.PathType
+	startPath       string
+	expResult       bool
+	expStrictResult bool
+}
+
+func TestSiadLinkSpeedStrictValidation(t *testing.T) {
+
+	// Each test is run against both the original and strict functions so the
+	// differences between the two are explicit.  First config entry is the
+	// node under test.
+	tests := []siadLinkSpeedStrictTestSpec{
+		{
+			name: "Non dp0xe interface - PASS",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0p1s2", "speed+auto"},
+			},
+			startPath:       "/interfaces/dataplane/speed",
+			expResult:       true,
+			expStrictResult: true,
+		},
+		{
+			name: "2 dp0xe interfaces in range, mismatched speeds - FAIL",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0xe22", "speed+25g"},
+				{"interfaces", "dataplane/tagnode+dp0xe23", "speed+10g"},
+			},
+			startPath:       "/interfaces/dataplane/speed",
+			expResult:       false,
+			expStrictResult: false,
+		},
+		{
+			name: "Disabled peer, remaining peers same speed - PASS",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0xe20", "speed+10g"},
+				{"interfaces", "dataplane/tagnode+dp0xe21", "speed+25g"},
+				{"interfaces", "dataplane/tagnode+dp0xe21", "disable%"},
+				{"interfaces", "dataplane/tagnode+dp0xe23", "speed+10g"},
+			},
+			startPath:       "/interfaces/dataplane/speed",
+			expResult:       true,
+			expStrictResult: true,
+		},
+		{
+			name: "Disabled peer, later peer mismatched - strict FAIL",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0xe20", "speed+10g"},
+				{"interfaces", "dataplane/tagnode+dp0xe21", "speed+10g"},
+				{"interfaces", "dataplane/tagnode+dp0xe21", "disable%"},
+				{"interfaces", "dataplane/tagnode+dp0xe23", "speed+25g"},
+			},
+			startPath:       "/interfaces/dataplane/speed",
+			expResult:       true,
+			expStrictResult: false,
+		},
+		{
+			name: "Peer with no speed node - strict FAIL",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0xe20", "speed+10g"},
+				{"interfaces", "dataplane/tagnode+dp0xe21", "mtu+1500"},
+			},
+			startPath:       "/interfaces/dataplane/speed",
+			expResult:       true,
+			expStrictResult: false,
+		},
+		{
+			name: "Disabled peer with no speed node - PASS",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0xe20", "speed+10g"},
+				{"interfaces", "dataplane/tagnode+dp0xe21", "disable%"},
+			},
+			startPath:       "/interfaces/dataplane/speed",
+			expResult:       true,
+			expStrictResult: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testTree := xpathtest.CreateTree(t, test.config)
+
+			testNode := testTree.FindFirstNode(
+				xutils.NewPathType(test.startPath))
+			ns := xpath.NewNodesetDatum([]xutils.XpathNode{testNode})
+			startIntfId := xpath.NewNumDatum(20)
+			endIntfId := xpath.NewNumDatum(23)
+
+			actResult :=
+				verifySiadLinkSpeed(
+					[]xpath.Datum{startIntfId, endIntfId, ns}).Boolean(
+					"(unused value)")
+			if test.expResult != actResult {
+				t.Fatalf("Unexpected result for %s: exp %t, got %t\n",
+					test.name, test.expResult, actResult)
+			}
+
+			actResult =
+				verifySiadLinkSpeedStrict(
+					[]xpath.Datum{startIntfId, endIntfId, ns}).Boolean(
+					"(unused value)")
+			if test.expStrictResult != actResult {
+				t.Fatalf("Unexpected strict result for %s: exp %t, got %t\n",
+					test.name, test.expStrictResult, actResult)
+			}
+		})
+	}
+}
+
+type speedTestSpec struct {
+	name       string
+	speed      string
+	minSpeed   string
+	expMbps    float64
+	expAtLeast bool
+}
+
+func TestSpeedFunctions(t *testing.T) {
+
+	tests := []speedTestSpec{
+		{
+			name:       "Gbit/s, at least lower speed",
+			speed:      "25g",
+			minSpeed:   "10g",
+			expMbps:    25000,
+			expAtLeast: true,
+		},
+		{
+			name:       "Gbit/s, at least same speed in other form",
+			speed:      "25G",
+			minSpeed:   "25000",
+			expMbps:    25000,
+			expAtLeast: true,
+		},
+		{
+			name:       "Fractional Gbit/s, not at least higher speed",
+			speed:      "2.5g",
+			minSpeed:   "10g",
+			expMbps:    2500,
+			expAtLeast: false,
+		},
+		{
+			name:       "Mbit/s, not at least higher speed",
+			speed:      "100m",
+			minSpeed:   "1g",
+			expMbps:    100,
+			expAtLeast: false,
+		},
+		{
+			name:       "Auto, at least any fixed speed",
+			speed:      "auto",
+			minSpeed:   "100g",
+			expMbps:    math.NaN(),
+			expAtLeast: true,
+		},
+		{
+			name:       "Fixed speed, not at least auto",
+			speed:      "100g",
+			minSpeed:   "auto",
+			expMbps:    100000,
+			expAtLeast: false,
+		},
+		{
+			name:       "Invalid speed",
+			speed:      "fast",
+			minSpeed:   "10g",
+			expMbps:    math.NaN(),
+			expAtLeast: false,
+		},
+		{
+			name:       "Invalid minimum speed",
+			speed:      "10g",
+			minSpeed:   "slow",
+			expMbps:    10000,
+			expAtLeast: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testTree := xpathtest.CreateTree(t, []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0p1s2",
+					"speed+" + test.speed},
+			})
+
+			testNode := testTree.FindFirstNode(
+				xutils.NewPathType("/interfaces/dataplane/speed"))
+			ns := xpath.NewNodesetDatum([]xutils.XpathNode{testNode})
+
+			actMbps := speedToMbps([]xpath.Datum{ns}).Number(
+				"(unused value)")
+			if !(actMbps == test.expMbps ||
+				math.IsNaN(actMbps) && math.IsNaN(test.expMbps)) {
+				t.Fatalf("Unexpected Mbps for %s: exp %v, got %v\n",
+					test.name, test.expMbps, actMbps)
+			}
+
+			actAtLeast := speedAtLeast([]xpath.Datum{
+				ns, xpath.NewLiteralDatum(test.minSpeed)}).Boolean(
+				"(unused value)")
+			if test.expAtLeast != actAtLeast {
+				t.Fatalf("Unexpected result for %s: exp %t, got %t\n",
+					test.name, test.expAtLeast, actAtLeast)
+			}
+		})
+	}
+}
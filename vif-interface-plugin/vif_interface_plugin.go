@@ -6,6 +6,10 @@
 package main
 
 import (
+	"net"
+	"strconv"
+	"strings"
+
 	"github.com/danos/xpath-plugins/common"
 	"github.com/danos/yang/xpath"
 	"github.com/danos/yang/xpath/xutils"
@@ -40,12 +44,25 @@ var RegistrationData = []xpath.CustomFunctionInfo{
 		RetType:       xpath.TypeIsBool,
 		DefaultRetVal: xpath.NewBoolDatum(false),
 	},
+	{
+		Name:          "verify-mtu-hierarchy",
+		FnPtr:         verifyMtuHierarchy,
+		Args:          []xpath.DatumTypeChecker{xpath.TypeIsNodeset},
+		RetType:       xpath.TypeIsBool,
+		DefaultRetVal: xpath.NewBoolDatum(false),
+	},
 }
 
 // Filters used to find required nodes. Values never change, so create once
 // and reuse.
+var addressFilter = common.GetFilter("address")
+var allChildrenFilter = common.GetFilter("*")
+var encapsulationFilter = common.GetFilter("encapsulation")
 var ifnameFilter = common.GetFilter("ifname")
 var innerVlanFilter = common.GetFilter("inner-vlan")
+var interfacesFilter = common.GetFilter("interfaces")
+var localIpFilter = common.GetFilter("local-ip")
+var mtuFilter = common.GetFilter("mtu")
 var nameFilter = common.GetFilter("name")
 var tagnodeFilter = common.GetFilter("tagnode")
 var vifFilter = common.GetFilter("vif")
@@ -113,6 +130,7 @@ type vifData struct {
 	vif       string
 	vlan      string
 	innerVlan string
+	mtu       string
 }
 
 func getVifData(intfNode xutils.XpathNode) map[string]vifData {
@@ -121,22 +139,28 @@ func getVifData(intfNode xutils.XpathNode) map[string]vifData {
 		intfNode, []xutils.XFilter{vifFilter})
 
 	vifs := make(map[string]vifData, len(vifNodes))
-	vifId, vlan, innerVlan := "", "", ""
 	for _, vifNode := range vifNodes {
-		vifId, _ = common.GetSingleChildValue(vifNode, tagnodeFilter)
-		vlan, _ = common.GetSingleChildValue(vifNode, vlanFilter)
-		innerVlan, _ = common.GetSingleChildValue(vifNode, innerVlanFilter)
-
-		vifs[vifId] = vifData{
-			vif:       vifId,
-			vlan:      vlan,
-			innerVlan: innerVlan,
-		}
+		vif := getSingleVifData(vifNode)
+		vifs[vif.vif] = vif
 	}
 
 	return vifs
 }
 
+func getSingleVifData(vifNode xutils.XpathNode) vifData {
+	vifId, _ := common.GetSingleChildValue(vifNode, tagnodeFilter)
+	vlan, _ := common.GetSingleChildValue(vifNode, vlanFilter)
+	innerVlan, _ := common.GetSingleChildValue(vifNode, innerVlanFilter)
+	mtu, _ := common.GetSingleChildValue(vifNode, mtuFilter)
+
+	return vifData{
+		vif:       vifId,
+		vlan:      vlan,
+		innerVlan: innerVlan,
+		mtu:       mtu,
+	}
+}
+
 func validateVifVlanSettings(
 	args []xpath.Datum,
 ) (retBool xpath.Datum) {
@@ -194,12 +218,15 @@ func checkVlanValuesDoNotConflictInternal(
 	// 'or (count(../vif[vlan=current()/vlan]) = 1)'
 	// 'or (count(../vif[vlan=current()/vlan]/inner-vlan) =
 	//	    count(../vif[vlan=current()/vlan]))'
+	//
+	// Only VIFs sharing the current VIF's vlan contribute to either count.
 	matchingVlanCount := 0
 	innerVlanCount := 0
 	for _, vif := range vifs {
-		if vif.vlan == currentVifVlanId {
-			matchingVlanCount++
+		if vif.vlan != currentVifVlanId {
+			continue
 		}
+		matchingVlanCount++
 		if vif.innerVlan != "" {
 			innerVlanCount++
 		}
@@ -354,3 +381,162 @@ func checkImplicitVlanIdUnique(
 	}
 	return xpath.NewBoolDatum(false)
 }
+
+// MTU used by any interface with no explicit mtu configured, and the
+// overhead of the extra (outer) tag carried by a QinQ VIF.
+const (
+	defaultMtu      = 1500
+	vlanTagOverhead = 4
+)
+
+// tunnelOverheads - size of the outer headers added by each tunnel
+// encapsulation, which must fit within the MTU of the underlying interface.
+var tunnelOverheads = map[string]int{
+	"gre":        24, // IPv4 + GRE
+	"gre-bridge": 38, // IPv4 + GRE + Ethernet
+	"ip6gre":     44, // IPv6 + GRE
+	"ip6ip6":     40,
+	"ipip":       20,
+	"ipip6":      40,
+	"sit":        20,
+}
+
+// parseMtu - return the MTU configured as mtu, or defMtu if none is set.
+// Returns false if mtu is set but is not a positive number.
+func parseMtu(mtu string, defMtu int) (int, bool) {
+	if mtu == "" {
+		return defMtu, true
+	}
+	value, err := strconv.Atoi(mtu)
+	if err != nil || value <= 0 {
+		return 0, false
+	}
+	return value, true
+}
+
+// getIntfMtu - return the effective MTU of an interface.
+func getIntfMtu(intfNode xutils.XpathNode) (int, bool) {
+	mtu, _ := common.GetSingleChildValue(intfNode, mtuFilter)
+	return parseMtu(mtu, defaultMtu)
+}
+
+// getVifMtuLimit - return the largest MTU a VIF may have on a parent with
+// the given MTU.  QinQ VIFs (those with an inner-vlan) lose space to the
+// outer tag.
+func getVifMtuLimit(parentMtu int, vif vifData) int {
+	if vif.innerVlan != "" {
+		return parentMtu - vlanTagOverhead
+	}
+	return parentMtu
+}
+
+// getVifMtu - return the effective MTU of a VIF, which is inherited from
+// its parent if not set explicitly.
+func getVifMtu(parentMtu int, vif vifData) (int, bool) {
+	return parseMtu(vif.mtu, getVifMtuLimit(parentMtu, vif))
+}
+
+// hasAddress - return true if any address of the interface or VIF, with
+// or without a prefix length, is ip.
+func hasAddress(intfNode xutils.XpathNode, ip net.IP) bool {
+	for _, addrNode := range intfNode.XChildren(
+		addressFilter, xutils.Unsorted) {
+		addr := strings.SplitN(addrNode.XValue(), "/", 2)[0]
+		if ip.Equal(net.ParseIP(addr)) {
+			return true
+		}
+	}
+	return false
+}
+
+// getTunnelUnderlayMtu - return the effective MTU of the interface or VIF
+// that owns the local-ip of the tunnel.  The second return value is false
+// if there is no local-ip or no interface is configured with it (eg it was
+// learnt from DHCP), in which case the underlying MTU is unknown.  The
+// third is false if an MTU along the way is invalid.
+func getTunnelUnderlayMtu(tunnelNode xutils.XpathNode) (int, bool, bool) {
+	localIp, _ := common.GetSingleChildValue(tunnelNode, localIpFilter)
+	ip := net.ParseIP(localIp)
+	if ip == nil {
+		return 0, false, true
+	}
+
+	intfNodes := common.GetDescendantNodesFromSingleNode(
+		tunnelNode.XRoot(),
+		[]xutils.XFilter{interfacesFilter, allChildrenFilter})
+	for _, intfNode := range intfNodes {
+		if hasAddress(intfNode, ip) {
+			intfMtu, ok := getIntfMtu(intfNode)
+			return intfMtu, true, ok
+		}
+		for _, vifNode := range intfNode.XChildren(
+			vifFilter, xutils.Unsorted) {
+			if !hasAddress(vifNode, ip) {
+				continue
+			}
+			intfMtu, ok := getIntfMtu(intfNode)
+			if !ok {
+				return 0, true, false
+			}
+			vifMtu, ok := getVifMtu(intfMtu, getSingleVifData(vifNode))
+			return vifMtu, true, ok
+		}
+	}
+
+	return 0, false, true
+}
+
+// verifyMtuHierarchy - implementation of verify-mtu-hierarchy(<nodeset>)
+//
+// Applied to an interface of any type.  Effective MTUs are the explicit
+// mtu if set, otherwise defaultMtu for an interface, or the largest MTU
+// permitted by the parent for a VIF.  Checks that:
+//
+//   - every explicit MTU used is a valid number
+//   - no VIF has an MTU greater than its parent's, less the outer tag
+//     overhead for QinQ VIFs
+//   - an explicit tunnel MTU plus its encapsulation overhead fits within
+//     the MTU of the interface or VIF owning its local-ip
+//
+//   configd:must "verify-mtu-hierarchy(.)"
+//
+func verifyMtuHierarchy(
+	args []xpath.Datum,
+) (retBool xpath.Datum) {
+
+	// Function has flexibility to be applied to '.' or any other node,
+	// rather than just the current node, but should only be applied to
+	// a single node.  So, if nodeset is empty or has multiple entries,
+	// return false.
+	ns0 := args[0].Nodeset("verify-mtu-hierarchy()")
+	if len(ns0) != 1 {
+		return xpath.NewBoolDatum(false)
+	}
+	intfNode := ns0[0]
+	intfMtu, ok := getIntfMtu(intfNode)
+	if !ok {
+		return xpath.NewBoolDatum(false)
+	}
+
+	for _, vif := range getVifData(intfNode) {
+		vifMtu, ok := getVifMtu(intfMtu, vif)
+		if !ok || vifMtu > getVifMtuLimit(intfMtu, vif) {
+			return xpath.NewBoolDatum(false)
+		}
+	}
+
+	if _, ok := common.GetSingleChildValue(intfNode, mtuFilter); !ok {
+		return xpath.NewBoolDatum(true)
+	}
+	encap, _ := common.GetSingleChildValue(intfNode, encapsulationFilter)
+	overhead, ok := tunnelOverheads[encap]
+	if !ok {
+		return xpath.NewBoolDatum(true)
+	}
+	underlayMtu, found, ok := getTunnelUnderlayMtu(intfNode)
+	if !ok || (found && intfMtu+overhead > underlayMtu) {
+		return xpath.NewBoolDatum(false)
+	}
+
+	return xpath.NewBoolDatum(true)
+}
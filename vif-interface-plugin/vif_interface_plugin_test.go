@@ -140,6 +140,23 @@ func TestCheckVlanValuesDoNotConflict(t *testing.T) {
 			},
 			expBoolResult: false,
 		},
+		{
+			// Inner-vlan on a VIF with a different vlan must not be counted.
+			name: "Conflicting VLANs: inner-vlan on other vlan - FAIL",
+			config: []xutils.PathType{
+				{"interfaces", "bonding/tagnode+dp0bond7", "vif/tagnode+44",
+					"vlan+777"},
+				{"interfaces", "bonding/tagnode+dp0bond7", "vif/tagnode+66",
+					"vlan+777"},
+				{"interfaces", "bonding/tagnode+dp0bond7", "vif/tagnode+66",
+					"inner-vlan+888"},
+				{"interfaces", "bonding/tagnode+dp0bond7", "vif/tagnode+88",
+					"vlan+999"},
+				{"interfaces", "bonding/tagnode+dp0bond7", "vif/tagnode+88",
+					"inner-vlan+111"},
+			},
+			expBoolResult: false,
+		},
 	}
 
 	for _, test := range tests {
@@ -174,6 +191,144 @@ func TestCheckVlanValuesDoNotConflict(t *testing.T) {
 	}
 }
 
+// vlanValuesDoNotConflictReference evaluates the original must statement
+// directly against the tree, step by step, without any of the caching or
+// shortcuts used by the plugin:
+//
+//   not(vlan) or (count(../vif[vlan=current()/vlan]) = 1) or
+//   (count(../vif[vlan=current()/vlan]/inner-vlan) =
+//    count(../vif[vlan=current()/vlan]))
+//
+func vlanValuesDoNotConflictReference(vifNode xutils.XpathNode) bool {
+
+	// 'not(vlan)'
+	currentVlans := vifNode.XChildren(vlanFilter, xutils.Unsorted)
+	if len(currentVlans) == 0 {
+		return true
+	}
+	currentVlan := currentVlans[0].XValue()
+
+	// '../vif[vlan=current()/vlan]'
+	var matchingVifs []xutils.XpathNode
+	for _, vif := range vifNode.XParent().XChildren(
+		vifFilter, xutils.Unsorted) {
+		for _, vlan := range vif.XChildren(vlanFilter, xutils.Unsorted) {
+			if vlan.XValue() == currentVlan {
+				matchingVifs = append(matchingVifs, vif)
+				break
+			}
+		}
+	}
+
+	// 'or (count(...) = 1)'
+	if len(matchingVifs) == 1 {
+		return true
+	}
+
+	// 'or (count(.../inner-vlan) = count(...))'
+	innerVlanCount := 0
+	for _, vif := range matchingVifs {
+		innerVlanCount += len(vif.XChildren(innerVlanFilter, xutils.Unsorted))
+	}
+	return innerVlanCount == len(matchingVifs)
+}
+
+// vlanSetting describes the vlan and inner-vlan (empty if not set) for a
+// single VIF in a generated test config.
+type vlanSetting struct {
+	vlan      string
+	innerVlan string
+}
+
+// Every combination of these settings is applied across the VIFs in
+// TestCheckVlanValuesDoNotConflictDifferential, giving a mix of plain,
+// QinQ and untagged VIFs sharing and not sharing outer vlans.
+var vlanSettings = []vlanSetting{
+	{"", ""},
+	{"", "10"},
+	{"100", ""},
+	{"100", "10"},
+	{"100", "20"},
+	{"200", ""},
+	{"200", "10"},
+}
+
+var differentialVifIds = []string{"1", "2", "3"}
+
+func createVlanSettingsConfig(settings []vlanSetting) []xutils.PathType {
+	var config []xutils.PathType
+	for i, setting := range settings {
+		vif := "vif/tagnode+" + differentialVifIds[i]
+		config = append(config, xutils.PathType{
+			"interfaces", "dataplane/tagnode+dp0s1", vif})
+		if setting.vlan != "" {
+			config = append(config, xutils.PathType{
+				"interfaces", "dataplane/tagnode+dp0s1", vif,
+				"vlan+" + setting.vlan})
+		}
+		if setting.innerVlan != "" {
+			config = append(config, xutils.PathType{
+				"interfaces", "dataplane/tagnode+dp0s1", vif,
+				"inner-vlan+" + setting.innerVlan})
+		}
+	}
+	return config
+}
+
+func TestCheckVlanValuesDoNotConflictDifferential(t *testing.T) {
+
+	numSettings := len(vlanSettings)
+	numConfigs := 1
+	for range differentialVifIds {
+		numConfigs *= numSettings
+	}
+
+	for cfgIndex := 0; cfgIndex < numConfigs; cfgIndex++ {
+		settings := make([]vlanSetting, len(differentialVifIds))
+		for i, index := 0, cfgIndex; i < len(settings); i++ {
+			settings[i] = vlanSettings[index%numSettings]
+			index /= numSettings
+		}
+
+		testTree := xpathtest.CreateTree(t, createVlanSettingsConfig(settings))
+		intfNode := testTree.FindFirstNode(
+			xutils.NewPathType("/interfaces/dataplane"))
+
+		expAllResult := true
+		for _, vifNode := range intfNode.XChildren(
+			vifFilter, xutils.Unsorted) {
+			expResult := vlanValuesDoNotConflictReference(vifNode)
+			if !expResult {
+				expAllResult = false
+			}
+
+			ns := xpath.NewNodesetDatum([]xutils.XpathNode{vifNode})
+			actResult :=
+				checkVlanValuesDoNotConflict([]xpath.Datum{ns}).Boolean(
+					"(unused value)")
+			if expResult != actResult {
+				t.Fatalf("Unexpected result for %v (vif %s): "+
+					"exp %t, got %t\n",
+					settings, vifNode.XChildren(
+						tagnodeFilter, xutils.Unsorted)[0].XValue(),
+					expResult, actResult)
+			}
+		}
+
+		// The interface level function also applies the implicit vlan
+		// check, but no VIF id here is ever used as an explicit vlan, so
+		// that check always passes.
+		ns := xpath.NewNodesetDatum([]xutils.XpathNode{intfNode})
+		actAllResult :=
+			validateVifVlanSettings([]xpath.Datum{ns}).Boolean(
+				"(unused value)")
+		if expAllResult != actAllResult {
+			t.Fatalf("Unexpected interface result for %v: exp %t, got %t\n",
+				settings, expAllResult, actAllResult)
+		}
+	}
+}
+
 func TestCheckImplicitVlanIdUnique(t *testing.T) {
 
 	tests := []vifInterfaceTestSpec{
@@ -247,3 +402,205 @@ func TestCheckImplicitVlanIdUnique(t *testing.T) {
 		})
 	}
 }
+
+// Tunnel local-ip owners are configured with a bare address as prefix
+// lengths can't be represented in test trees; both forms are matched.
+func TestVerifyMtuHierarchy(t *testing.T) {
+
+	tests := []vifInterfaceTestSpec{
+		{
+			name: "All default MTUs - PASS",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "vif/tagnode+10"},
+				{"interfaces", "dataplane/tagnode+dp0s1", "vif/tagnode+20",
+					"inner-vlan+30"},
+			},
+			startPath:     "/interfaces/dataplane",
+			expBoolResult: true,
+		},
+		{
+			name: "VIF MTU equal to parent - PASS",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "mtu+9000"},
+				{"interfaces", "dataplane/tagnode+dp0s1", "vif/tagnode+10",
+					"mtu+9000"},
+			},
+			startPath:     "/interfaces/dataplane",
+			expBoolResult: true,
+		},
+		{
+			name: "VIF MTU above default parent MTU - FAIL",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "vif/tagnode+10",
+					"mtu+1600"},
+			},
+			startPath:     "/interfaces/dataplane",
+			expBoolResult: false,
+		},
+		{
+			name: "VIF MTU above explicit parent MTU - FAIL",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "mtu+1400"},
+				{"interfaces", "dataplane/tagnode+dp0s1", "vif/tagnode+10",
+					"mtu+1500"},
+			},
+			startPath:     "/interfaces/dataplane",
+			expBoolResult: false,
+		},
+		{
+			name: "QinQ VIF MTU within tag overhead - PASS",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "vif/tagnode+10",
+					"inner-vlan+30"},
+				{"interfaces", "dataplane/tagnode+dp0s1", "vif/tagnode+10",
+					"mtu+1496"},
+			},
+			startPath:     "/interfaces/dataplane",
+			expBoolResult: true,
+		},
+		{
+			name: "QinQ VIF MTU equal to parent - FAIL",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "vif/tagnode+10",
+					"inner-vlan+30"},
+				{"interfaces", "dataplane/tagnode+dp0s1", "vif/tagnode+10",
+					"mtu+1500"},
+			},
+			startPath:     "/interfaces/dataplane",
+			expBoolResult: false,
+		},
+		{
+			name: "Invalid interface MTU - FAIL",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "mtu+jumbo"},
+				{"interfaces", "dataplane/tagnode+dp0s1", "vif/tagnode+10"},
+			},
+			startPath:     "/interfaces/dataplane",
+			expBoolResult: false,
+		},
+		{
+			name: "Invalid VIF MTU - FAIL",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "vif/tagnode+10",
+					"mtu+jumbo"},
+			},
+			startPath:     "/interfaces/dataplane",
+			expBoolResult: false,
+		},
+		{
+			name: "Invalid tunnel underlay MTU - FAIL",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "address@10.0.0.1"},
+				{"interfaces", "dataplane/tagnode+dp0s1", "mtu+0"},
+				{"interfaces", "tunnel/tagnode+tun0", "encapsulation+gre"},
+				{"interfaces", "tunnel/tagnode+tun0", "local-ip+10.0.0.1"},
+				{"interfaces", "tunnel/tagnode+tun0", "mtu+1400"},
+			},
+			startPath:     "/interfaces/tunnel",
+			expBoolResult: false,
+		},
+		{
+			name: "Tunnel with default MTU - PASS",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "address@10.0.0.1"},
+				{"interfaces", "tunnel/tagnode+tun0", "encapsulation+gre"},
+				{"interfaces", "tunnel/tagnode+tun0", "local-ip+10.0.0.1"},
+			},
+			startPath:     "/interfaces/tunnel",
+			expBoolResult: true,
+		},
+		{
+			name: "GRE tunnel MTU within overhead - PASS",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "address@10.0.0.1"},
+				{"interfaces", "tunnel/tagnode+tun0", "encapsulation+gre"},
+				{"interfaces", "tunnel/tagnode+tun0", "local-ip+10.0.0.1"},
+				{"interfaces", "tunnel/tagnode+tun0", "mtu+1476"},
+			},
+			startPath:     "/interfaces/tunnel",
+			expBoolResult: true,
+		},
+		{
+			name: "GRE tunnel MTU exceeds overhead - FAIL",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "address@10.0.0.1"},
+				{"interfaces", "tunnel/tagnode+tun0", "encapsulation+gre"},
+				{"interfaces", "tunnel/tagnode+tun0", "local-ip+10.0.0.1"},
+				{"interfaces", "tunnel/tagnode+tun0", "mtu+1480"},
+			},
+			startPath:     "/interfaces/tunnel",
+			expBoolResult: false,
+		},
+		{
+			name: "GRE tunnel over jumbo interface - PASS",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "address@10.0.0.1"},
+				{"interfaces", "dataplane/tagnode+dp0s1", "mtu+9000"},
+				{"interfaces", "tunnel/tagnode+tun0", "encapsulation+gre"},
+				{"interfaces", "tunnel/tagnode+tun0", "local-ip+10.0.0.1"},
+				{"interfaces", "tunnel/tagnode+tun0", "mtu+8000"},
+			},
+			startPath:     "/interfaces/tunnel",
+			expBoolResult: true,
+		},
+		{
+			name: "Tunnel over QinQ VIF inheriting parent MTU - FAIL",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "mtu+1600"},
+				{"interfaces", "dataplane/tagnode+dp0s1", "vif/tagnode+10",
+					"inner-vlan+30"},
+				{"interfaces", "dataplane/tagnode+dp0s1", "vif/tagnode+10",
+					"address@10.0.0.1"},
+				{"interfaces", "tunnel/tagnode+tun0", "encapsulation+ipip"},
+				{"interfaces", "tunnel/tagnode+tun0", "local-ip+10.0.0.1"},
+				{"interfaces", "tunnel/tagnode+tun0", "mtu+1580"},
+			},
+			startPath:     "/interfaces/tunnel",
+			expBoolResult: false,
+		},
+		{
+			name: "Tunnel over QinQ VIF inheriting parent MTU - PASS",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "mtu+1600"},
+				{"interfaces", "dataplane/tagnode+dp0s1", "vif/tagnode+10",
+					"inner-vlan+30"},
+				{"interfaces", "dataplane/tagnode+dp0s1", "vif/tagnode+10",
+					"address@10.0.0.1"},
+				{"interfaces", "tunnel/tagnode+tun0", "encapsulation+ipip"},
+				{"interfaces", "tunnel/tagnode+tun0", "local-ip+10.0.0.1"},
+				{"interfaces", "tunnel/tagnode+tun0", "mtu+1576"},
+			},
+			startPath:     "/interfaces/tunnel",
+			expBoolResult: true,
+		},
+		{
+			name: "Tunnel with unknown local-ip owner - PASS",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "address@dhcp"},
+				{"interfaces", "tunnel/tagnode+tun0", "encapsulation+gre"},
+				{"interfaces", "tunnel/tagnode+tun0", "local-ip+10.0.0.1"},
+				{"interfaces", "tunnel/tagnode+tun0", "mtu+9000"},
+			},
+			startPath:     "/interfaces/tunnel",
+			expBoolResult: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testTree := xpathtest.CreateTree(t, test.config)
+
+			testNode := testTree.FindFirstNode(
+				xutils.NewPathType(test.startPath))
+			ns := xpath.NewNodesetDatum([]xutils.XpathNode{testNode})
+
+			actResult :=
+				verifyMtuHierarchy([]xpath.Datum{ns}).Boolean(
+					"(unused value)")
+			if test.expBoolResult != actResult {
+				t.Fatalf("Unexpected result for %s: exp %t, got %t\n",
+					test.name, test.expBoolResult, actResult)
+			}
+		})
+	}
+}
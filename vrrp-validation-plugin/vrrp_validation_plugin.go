@@ -0,0 +1,213 @@
+// Copyright (c) 2021, AT&T Intellectual Property Inc.
+// All rights reserved.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"net"
+	"strings"
+
+	"github.com/danos/xpath-plugins/common"
+	"github.com/danos/yang/xpath"
+	"github.com/danos/yang/xpath/xutils"
+)
+
+var RegistrationData = []xpath.CustomFunctionInfo{
+	{
+		Name:          "verify-vrrp-group",
+		FnPtr:         verifyVrrpGroup,
+		Args:          []xpath.DatumTypeChecker{xpath.TypeIsNodeset},
+		RetType:       xpath.TypeIsBool,
+		DefaultRetVal: xpath.NewBoolDatum(false),
+	},
+}
+
+const (
+	// Priority reserved for the router that owns the virtual addresses,
+	// ie has them configured as interface addresses.
+	ownerPriority = "255"
+	preemptFalse  = "false"
+)
+
+// Filters used to find required nodes. Values never change, so create once
+// and reuse.
+var addressFilter = common.GetFilter("address")
+var preemptDelayFilter = common.GetFilter("preempt-delay")
+var preemptFilter = common.GetFilter("preempt")
+var priorityFilter = common.GetFilter("priority")
+var vifFilter = common.GetFilter("vif")
+var virtualAddressFilter = common.GetFilter("virtual-address")
+var vrrpFilter = common.GetFilter("vrrp")
+var vrrpGroupFilter = common.GetFilter("vrrp-group")
+
+// vrrpGroupData - settings of a single VRRP group.
+type vrrpGroupData struct {
+	id              string
+	priority        string
+	preempt         string
+	hasPreemptDelay bool
+	virtualAddrs    []string
+}
+
+// vrrpIntfData - addresses and VRRP groups of a single interface or VIF.
+type vrrpIntfData struct {
+	addresses []string
+	groups    []vrrpGroupData
+}
+
+func getVrrpIntfData(intfNode xutils.XpathNode) vrrpIntfData {
+	var intf vrrpIntfData
+	for _, addrNode := range intfNode.XChildren(
+		addressFilter, xutils.Unsorted) {
+		intf.addresses = append(intf.addresses, addrNode.XValue())
+	}
+
+	groupNodes := common.GetDescendantNodesFromSingleNode(
+		intfNode, []xutils.XFilter{vrrpFilter, vrrpGroupFilter})
+	for _, groupNode := range groupNodes {
+		group := vrrpGroupData{id: groupNode.XValue()}
+		group.priority, _ = common.GetSingleChildValue(
+			groupNode, priorityFilter)
+		group.preempt, _ = common.GetSingleChildValue(
+			groupNode, preemptFilter)
+		_, group.hasPreemptDelay = common.GetSingleChildValue(
+			groupNode, preemptDelayFilter)
+		for _, vaddrNode := range groupNode.XChildren(
+			virtualAddressFilter, xutils.Unsorted) {
+			group.virtualAddrs = append(group.virtualAddrs, vaddrNode.XValue())
+		}
+		intf.groups = append(intf.groups, group)
+	}
+
+	return intf
+}
+
+// verifyVrrpGroup - implementation of verify-vrrp-group(<nodeset>)
+//
+// Applied to an interface, and checks the VRRP groups on it and on each of
+// its VIFs.  For each interface or VIF:
+//
+//   - group IDs are unique
+//   - no virtual address is used by more than one group
+//   - preempt-delay is not set when preempt is disabled
+//   - a group with priority 255 owns its virtual addresses, ie they are
+//     all interface addresses, and conversely a group whose virtual
+//     addresses include an interface address has priority 255
+//   - each virtual address is in a subnet configured on the interface,
+//     other than IPv6 link-local addresses.  This is skipped for
+//     interfaces with a DHCP address, as the subnet is not known.
+//
+//   configd:must "verify-vrrp-group(.)"
+//
+func verifyVrrpGroup(
+	args []xpath.Datum,
+) (retBool xpath.Datum) {
+
+	// Function has flexibility to be applied to '.' or any other node,
+	// rather than just the current node, but should only be applied to
+	// a single node.  So, if nodeset is empty or has multiple entries,
+	// return false.
+	ns0 := args[0].Nodeset("verify-vrrp-group()")
+	if len(ns0) != 1 {
+		return xpath.NewBoolDatum(false)
+	}
+	intfNode := ns0[0]
+
+	intfNodes := []xutils.XpathNode{intfNode}
+	intfNodes = append(intfNodes,
+		intfNode.XChildren(vifFilter, xutils.Unsorted)...)
+	for _, node := range intfNodes {
+		if !verifyVrrpIntfData(getVrrpIntfData(node)) {
+			return xpath.NewBoolDatum(false)
+		}
+	}
+
+	return xpath.NewBoolDatum(true)
+}
+
+func verifyVrrpIntfData(intf vrrpIntfData) bool {
+	subnets, subnetsKnown := getIntfSubnets(intf.addresses)
+
+	groupIds := make(map[string]bool, len(intf.groups))
+	virtualAddrs := make(map[string]bool)
+	for _, group := range intf.groups {
+		if groupIds[group.id] {
+			return false
+		}
+		groupIds[group.id] = true
+
+		if group.preempt == preemptFalse && group.hasPreemptDelay {
+			return false
+		}
+
+		isOwner := group.priority == ownerPriority
+		for _, vaddrStr := range group.virtualAddrs {
+			vaddr := parseVirtualAddress(vaddrStr)
+			if vaddr == nil {
+				return false
+			}
+			if virtualAddrs[vaddr.String()] {
+				return false
+			}
+			virtualAddrs[vaddr.String()] = true
+
+			if isIntfAddress(vaddr, subnets) != isOwner {
+				return false
+			}
+			if subnetsKnown && !vaddr.IsLinkLocalUnicast() &&
+				!isInSubnet(vaddr, subnets) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// getIntfSubnets - parse the interface addresses, returning false if any
+// of them (eg 'dhcp') do not give a known subnet.
+func getIntfSubnets(addresses []string) ([]*net.IPNet, bool) {
+	subnetsKnown := true
+	var subnets []*net.IPNet
+	for _, addr := range addresses {
+		ip, subnet, err := net.ParseCIDR(addr)
+		if err != nil {
+			subnetsKnown = false
+			continue
+		}
+		// Keep the interface's own address, rather than the network
+		// address, so owner addresses can be identified.
+		subnet.IP = ip
+		subnets = append(subnets, subnet)
+	}
+	return subnets, subnetsKnown
+}
+
+// parseVirtualAddress - virtual addresses may optionally include a prefix
+// length, which is ignored here.
+func parseVirtualAddress(vaddr string) net.IP {
+	if i := strings.Index(vaddr, "/"); i >= 0 {
+		vaddr = vaddr[:i]
+	}
+	return net.ParseIP(vaddr)
+}
+
+func isIntfAddress(addr net.IP, subnets []*net.IPNet) bool {
+	for _, subnet := range subnets {
+		if subnet.IP.Equal(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func isInSubnet(addr net.IP, subnets []*net.IPNet) bool {
+	for _, subnet := range subnets {
+		if subnet.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,279 @@
+// Copyright (c) 2021, AT&T Intellectual Property. All rights reserved.
+//
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"testing"
+
+	"github.com/danos/yang/xpath"
+	"github.com/danos/yang/xpath/xpathtest"
+	"github.com/danos/yang/xpath/xutils"
+)
+
+type vrrpTestSpec struct {
+	name      string
+	config    []xutils.PathType
+	expResult bool
+}
+
+// Interface addresses include a prefix length, which can't be represented
+// in a test tree path, so only 'dhcp' is used in the tree tests.  Subnet
+// handling is covered by TestVrrpIntfData.
+func TestVrrpGroup(t *testing.T) {
+
+	tests := []vrrpTestSpec{
+		{
+			name: "Single group - PASS",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "address@dhcp"},
+				{"interfaces", "dataplane/tagnode+dp0s1", "vrrp",
+					"vrrp-group/tagnode+1", "virtual-address@10.0.0.100"},
+			},
+			expResult: true,
+		},
+		{
+			name: "Same group ID on interface and VIF - PASS",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "address@dhcp"},
+				{"interfaces", "dataplane/tagnode+dp0s1", "vrrp",
+					"vrrp-group/tagnode+1", "virtual-address@10.0.0.100"},
+				{"interfaces", "dataplane/tagnode+dp0s1", "vif/tagnode+10",
+					"address@dhcp"},
+				{"interfaces", "dataplane/tagnode+dp0s1", "vif/tagnode+10",
+					"vrrp", "vrrp-group/tagnode+1",
+					"virtual-address@10.0.0.100"},
+			},
+			expResult: true,
+		},
+		{
+			name: "Virtual address in two groups - FAIL",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "address@dhcp"},
+				{"interfaces", "dataplane/tagnode+dp0s1", "vrrp",
+					"vrrp-group/tagnode+1", "virtual-address@10.0.0.100"},
+				{"interfaces", "dataplane/tagnode+dp0s1", "vrrp",
+					"vrrp-group/tagnode+2", "virtual-address@10.0.0.100"},
+			},
+			expResult: false,
+		},
+		{
+			name: "Virtual address in two groups on VIF - FAIL",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "vif/tagnode+10",
+					"address@dhcp"},
+				{"interfaces", "dataplane/tagnode+dp0s1", "vif/tagnode+10",
+					"vrrp", "vrrp-group/tagnode+1",
+					"virtual-address@10.0.0.100"},
+				{"interfaces", "dataplane/tagnode+dp0s1", "vif/tagnode+10",
+					"vrrp", "vrrp-group/tagnode+2",
+					"virtual-address@10.0.0.100"},
+			},
+			expResult: false,
+		},
+		{
+			name: "Preempt delay with preempt - PASS",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "vrrp",
+					"vrrp-group/tagnode+1", "preempt+true"},
+				{"interfaces", "dataplane/tagnode+dp0s1", "vrrp",
+					"vrrp-group/tagnode+1", "preempt-delay+10"},
+			},
+			expResult: true,
+		},
+		{
+			name: "Preempt delay without preempt - FAIL",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "vrrp",
+					"vrrp-group/tagnode+1", "preempt+false"},
+				{"interfaces", "dataplane/tagnode+dp0s1", "vrrp",
+					"vrrp-group/tagnode+1", "preempt-delay+10"},
+			},
+			expResult: false,
+		},
+		{
+			name: "Owner priority without interface address - FAIL",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "address@dhcp"},
+				{"interfaces", "dataplane/tagnode+dp0s1", "vrrp",
+					"vrrp-group/tagnode+1", "priority+255"},
+				{"interfaces", "dataplane/tagnode+dp0s1", "vrrp",
+					"vrrp-group/tagnode+1", "virtual-address@10.0.0.100"},
+			},
+			expResult: false,
+		},
+		{
+			name: "Invalid virtual address - FAIL",
+			config: []xutils.PathType{
+				{"interfaces", "dataplane/tagnode+dp0s1", "vrrp",
+					"vrrp-group/tagnode+1", "virtual-address@10.0.0.300"},
+			},
+			expResult: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testTree := xpathtest.CreateTree(t, test.config)
+
+			testNode := testTree.FindFirstNode(
+				xutils.NewPathType("/interfaces/dataplane"))
+			ns := xpath.NewNodesetDatum([]xutils.XpathNode{testNode})
+
+			actResult := verifyVrrpGroup(
+				[]xpath.Datum{ns}).Boolean("(unused value)")
+			if test.expResult != actResult {
+				t.Fatalf("Unexpected result for %s: exp %t, got %t\n",
+					test.name, test.expResult, actResult)
+			}
+		})
+	}
+}
+
+type vrrpIntfDataTestSpec struct {
+	name      string
+	intf      vrrpIntfData
+	expResult bool
+}
+
+func TestVrrpIntfData(t *testing.T) {
+
+	tests := []vrrpIntfDataTestSpec{
+		{
+			name: "Virtual address in subnet - PASS",
+			intf: vrrpIntfData{
+				addresses: []string{"10.0.0.1/24"},
+				groups: []vrrpGroupData{
+					{id: "1", virtualAddrs: []string{"10.0.0.100"}},
+				},
+			},
+			expResult: true,
+		},
+		{
+			name: "Virtual address with prefix length in subnet - PASS",
+			intf: vrrpIntfData{
+				addresses: []string{"10.0.0.1/24"},
+				groups: []vrrpGroupData{
+					{id: "1", virtualAddrs: []string{"10.0.0.100/24"}},
+				},
+			},
+			expResult: true,
+		},
+		{
+			name: "Virtual address in second subnet - PASS",
+			intf: vrrpIntfData{
+				addresses: []string{"10.0.0.1/24", "10.0.1.1/24"},
+				groups: []vrrpGroupData{
+					{id: "1", virtualAddrs: []string{"10.0.1.100"}},
+				},
+			},
+			expResult: true,
+		},
+		{
+			name: "Virtual address outside subnet - FAIL",
+			intf: vrrpIntfData{
+				addresses: []string{"10.0.0.1/24"},
+				groups: []vrrpGroupData{
+					{id: "1", virtualAddrs: []string{"10.0.1.100"}},
+				},
+			},
+			expResult: false,
+		},
+		{
+			name: "Virtual address without interface address - FAIL",
+			intf: vrrpIntfData{
+				groups: []vrrpGroupData{
+					{id: "1", virtualAddrs: []string{"10.0.1.100"}},
+				},
+			},
+			expResult: false,
+		},
+		{
+			name: "Virtual address with DHCP interface address - PASS",
+			intf: vrrpIntfData{
+				addresses: []string{"dhcp"},
+				groups: []vrrpGroupData{
+					{id: "1", virtualAddrs: []string{"10.0.1.100"}},
+				},
+			},
+			expResult: true,
+		},
+		{
+			name: "IPv6 link-local virtual address - PASS",
+			intf: vrrpIntfData{
+				addresses: []string{"2001:db8::1/64"},
+				groups: []vrrpGroupData{
+					{id: "1", virtualAddrs: []string{
+						"fe80::1", "2001:db8::100"}},
+				},
+			},
+			expResult: true,
+		},
+		{
+			name: "Owner with priority 255 - PASS",
+			intf: vrrpIntfData{
+				addresses: []string{"10.0.0.1/24"},
+				groups: []vrrpGroupData{
+					{id: "1", priority: "255",
+						virtualAddrs: []string{"10.0.0.1"}},
+				},
+			},
+			expResult: true,
+		},
+		{
+			name: "Owner without priority 255 - FAIL",
+			intf: vrrpIntfData{
+				addresses: []string{"10.0.0.1/24"},
+				groups: []vrrpGroupData{
+					{id: "1", priority: "200",
+						virtualAddrs: []string{"10.0.0.1"}},
+				},
+			},
+			expResult: false,
+		},
+		{
+			name: "Priority 255 without owning address - FAIL",
+			intf: vrrpIntfData{
+				addresses: []string{"10.0.0.1/24"},
+				groups: []vrrpGroupData{
+					{id: "1", priority: "255",
+						virtualAddrs: []string{"10.0.0.100"}},
+				},
+			},
+			expResult: false,
+		},
+		{
+			name: "Duplicate group ID - FAIL",
+			intf: vrrpIntfData{
+				addresses: []string{"10.0.0.1/24"},
+				groups: []vrrpGroupData{
+					{id: "1", virtualAddrs: []string{"10.0.0.100"}},
+					{id: "1", virtualAddrs: []string{"10.0.0.101"}},
+				},
+			},
+			expResult: false,
+		},
+		{
+			name: "Same virtual address written differently - FAIL",
+			intf: vrrpIntfData{
+				addresses: []string{"2001:db8::1/64"},
+				groups: []vrrpGroupData{
+					{id: "1", virtualAddrs: []string{"2001:db8::100"}},
+					{id: "2", virtualAddrs: []string{"2001:db8:0::100/64"}},
+				},
+			},
+			expResult: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actResult := verifyVrrpIntfData(test.intf)
+			if test.expResult != actResult {
+				t.Fatalf("Unexpected result for %s: exp %t, got %t\n",
+					test.name, test.expResult, actResult)
+			}
+		})
+	}
+}